@@ -0,0 +1,58 @@
+package confutil
+
+import (
+	"time"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+// conf 包中配置消息的内置校验规则
+func init() {
+	RegisterRules(&conf.App{},
+		Func("env", func(app *conf.App) error {
+			if app.GetEnv() == "" {
+				return nil
+			}
+			_, err := conf.ParseEnv(app.GetEnv())
+			return err
+		}),
+	)
+	RegisterRules(&conf.Data_Database{},
+		// dbutil.New 只能打开 mysql
+		Required("driver"),
+		OneOf("driver", "mysql"),
+		Range("max_open_connections", 0, 10000),
+		Range("max_idle_connections", 0, 10000),
+		LessOrEqual("max_idle_connections", "max_open_connections"),
+		DurationRange("connection_life_time", 0, 24*time.Hour),
+		Range("port", 0, 65535),
+		DefinedEnum("tls_mode"),
+	)
+	RegisterRules(&conf.Data_Redis{},
+		OneOf("network", "tcp", "unix"),
+		Range("db", 0, 65535),
+		Range("pool_size", 0, 100000),
+		Range("min_idle_conns", 0, 100000),
+		LessOrEqual("min_idle_conns", "pool_size"),
+	)
+	RegisterRules(&conf.Server_HTTP{},
+		DurationRange("timeout", 0, 0),
+		DurationRange("read_timeout", 0, 0),
+		DurationRange("write_timeout", 0, 0),
+		DurationRange("idle_timeout", 0, 0),
+		DurationRange("shutdown_timeout", 0, 0),
+	)
+	RegisterRules(&conf.Server_GRPC{},
+		DurationRange("timeout", 0, 0),
+		DurationRange("shutdown_timeout", 0, 0),
+		Range("max_recv_msg_size", 0, 1<<31-1),
+		Range("max_send_msg_size", 0, 1<<31-1),
+	)
+	RegisterRules(&conf.Log{},
+		Pattern("level", `^(?i)(debug|info|warn|warning|error)$`),
+		DefinedEnum("format"),
+	)
+	RegisterRules(&conf.Trace{},
+		Range("sample_ratio", 0, 1),
+	)
+}
@@ -0,0 +1,172 @@
+package confutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+const durationFullName = "google.protobuf.Duration"
+
+// readFile 按扩展名解析配置文件
+func readFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values, err := decode(filepath.Ext(path), data)
+	if err != nil {
+		return nil, fmt.Errorf("confutil: %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// decode 解析 YAML、JSON 或 TOML 内容
+func decode(ext string, data []byte) (map[string]any, error) {
+	values := make(map[string]any)
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+	case ".json":
+		decoder := json.NewDecoder(strings.NewReader(string(data)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, err
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", ext)
+	}
+	return values, nil
+}
+
+// normalize 按消息描述将 key 统一为 proto 字段名（兼容 json 驼峰名），
+// 并将 Duration 字段的 Go 时长写法（如 1m30s、500ms）转换为 protojson 格式
+func normalize(values map[string]any, md protoreflect.MessageDescriptor) (map[string]any, error) {
+	result := make(map[string]any, len(values))
+	for key, value := range values {
+		fd := lookupField(md, key)
+		if fd == nil {
+			// 交给 protojson 报告未知字段
+			result[key] = value
+			continue
+		}
+		normalized, err := normalizeField(fd, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fd.Name(), err)
+		}
+		result[string(fd.Name())] = normalized
+	}
+	return result, nil
+}
+
+func normalizeField(fd protoreflect.FieldDescriptor, value any) (any, error) {
+	switch {
+	case fd.IsMap():
+		m, ok := value.(map[string]any)
+		if !ok || fd.MapValue().Message() == nil {
+			return value, nil
+		}
+		result := make(map[string]any, len(m))
+		for key, item := range m {
+			normalized, err := normalizeValue(fd.MapValue().Message(), item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			result[key] = normalized
+		}
+		return result, nil
+	case fd.IsList():
+		list, ok := value.([]any)
+		if !ok || fd.Message() == nil {
+			return value, nil
+		}
+		result := make([]any, 0, len(list))
+		for i, item := range list {
+			normalized, err := normalizeValue(fd.Message(), item)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			result = append(result, normalized)
+		}
+		return result, nil
+	case fd.Message() != nil:
+		return normalizeValue(fd.Message(), value)
+	}
+	return value, nil
+}
+
+func normalizeValue(md protoreflect.MessageDescriptor, value any) (any, error) {
+	if md.FullName() == durationFullName {
+		return normalizeDuration(value)
+	}
+	if m, ok := value.(map[string]any); ok {
+		return normalize(m, md)
+	}
+	return value, nil
+}
+
+// normalizeDuration 支持 Go 时长字符串和以秒为单位的数字
+func normalizeDuration(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		return formatDuration(d), nil
+	case int:
+		return formatDuration(time.Duration(v) * time.Second), nil
+	case int64:
+		return formatDuration(time.Duration(v) * time.Second), nil
+	case float64:
+		return formatDuration(time.Duration(v * float64(time.Second))), nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return formatDuration(time.Duration(f * float64(time.Second))), nil
+	}
+	return value, nil
+}
+
+// formatDuration 格式化为 protojson 的 Duration 格式，例如 90s、0.5s
+func formatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		if d == math.MinInt64 {
+			d++
+		}
+		d = -d
+	}
+	seconds, nanos := int64(d/time.Second), int64(d%time.Second)
+	if nanos == 0 {
+		return sign + strconv.FormatInt(seconds, 10) + "s"
+	}
+	fraction := strings.TrimRight(fmt.Sprintf("%09d", nanos), "0")
+	return sign + strconv.FormatInt(seconds, 10) + "." + fraction + "s"
+}
+
+// lookupField 按 proto 字段名或 json 名查找字段
+func lookupField(md protoreflect.MessageDescriptor, key string) protoreflect.FieldDescriptor {
+	if fd := md.Fields().ByName(protoreflect.Name(key)); fd != nil {
+		return fd
+	}
+	return md.Fields().ByJSONName(key)
+}
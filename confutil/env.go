@@ -0,0 +1,144 @@
+package confutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// maxFieldDepth 展开字段路径的最大嵌套深度，防止递归消息无限展开
+const maxFieldDepth = 8
+
+// fieldPath 叶子字段及其 proto 字段名路径
+type fieldPath struct {
+	path []string
+	fd   protoreflect.FieldDescriptor
+}
+
+// leafFields 展开消息中所有可以用单个字符串赋值的字段：标量、枚举、Duration 和标量列表
+func leafFields(md protoreflect.MessageDescriptor) []fieldPath {
+	var paths []fieldPath
+	var walk func(md protoreflect.MessageDescriptor, prefix []string)
+	walk = func(md protoreflect.MessageDescriptor, prefix []string) {
+		fields := md.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			path := append(append([]string(nil), prefix...), string(fd.Name()))
+			switch {
+			case fd.IsMap():
+				continue
+			case fd.IsList():
+				if fd.Message() == nil {
+					paths = append(paths, fieldPath{path: path, fd: fd})
+				}
+			case fd.Message() != nil:
+				if fd.Message().FullName() == durationFullName {
+					paths = append(paths, fieldPath{path: path, fd: fd})
+				} else if len(path) < maxFieldDepth && !strings.HasPrefix(string(fd.Message().FullName()), "google.protobuf.") {
+					walk(fd.Message(), path)
+				}
+			default:
+				paths = append(paths, fieldPath{path: path, fd: fd})
+			}
+		}
+	}
+	walk(md, nil)
+	return paths
+}
+
+// envName 字段路径对应的环境变量名
+func envName(prefix string, path []string) string {
+	return strings.ToUpper(prefix + "_" + strings.Join(path, "_"))
+}
+
+// envLayer 从环境变量中读取覆盖值
+func envLayer(environ []string, prefix string, md protoreflect.MessageDescriptor) (map[string]any, error) {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+	layer := make(map[string]any)
+	for _, leaf := range leafFields(md) {
+		raw, ok := env[envName(prefix, leaf.path)]
+		if !ok {
+			continue
+		}
+		value, err := parseFieldValue(leaf.fd, raw)
+		if err != nil {
+			return nil, fmt.Errorf("confutil: env %s: %w", envName(prefix, leaf.path), err)
+		}
+		setPath(layer, leaf.path, value)
+	}
+	return layer, nil
+}
+
+// parseFieldValue 将字符串按字段类型转换为 protojson 可以解析的值，列表以逗号分隔
+func parseFieldValue(fd protoreflect.FieldDescriptor, raw string) (any, error) {
+	if fd.IsList() {
+		var values []any
+		for _, item := range strings.Split(raw, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			value, err := parseScalar(fd, item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+	return parseScalar(fd, raw)
+}
+
+func parseScalar(fd protoreflect.FieldDescriptor, raw string) (any, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return strconv.ParseBool(raw)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, err
+		}
+		return json.Number(raw), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if _, err := strconv.ParseUint(raw, 10, 64); err != nil {
+			return nil, err
+		}
+		return json.Number(raw), nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, err
+		}
+		return json.Number(raw), nil
+	case protoreflect.EnumKind:
+		if _, err := strconv.ParseInt(raw, 10, 32); err == nil {
+			return json.Number(raw), nil
+		}
+		return raw, nil
+	case protoreflect.MessageKind:
+		if fd.Message().FullName() == durationFullName {
+			return normalizeDuration(raw)
+		}
+	}
+	return raw, nil
+}
+
+// setPath 按路径写入嵌套 map
+func setPath(values map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := values[key].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			values[key] = next
+		}
+		values = next
+	}
+	values[path[len(path)-1]] = value
+}
@@ -0,0 +1,100 @@
+package confutil
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Flags 由配置消息字段生成的命令行参数，作为最后一层覆盖配置
+type Flags struct {
+	fields []*fieldFlag
+}
+
+// BindFlags 为消息中的每个标量、枚举、Duration 和标量列表字段在 fs 上注册命令行参数，
+// 参数名为点分隔的字段路径，例如 --data.database.max_open_connections=50，列表以逗号分隔。
+// 需要在 fs.Parse 之前调用，并通过 WithFlags 交给 Loader；参数名与 fs 中已有参数冲突时 panic
+func BindFlags(fs *flag.FlagSet, m proto.Message) *Flags {
+	f := &Flags{}
+	for _, leaf := range leafFields(m.ProtoReflect().Descriptor()) {
+		ff := &fieldFlag{leaf: leaf}
+		f.fields = append(f.fields, ff)
+		fs.Var(ff, strings.Join(leaf.path, "."), flagUsage(leaf.fd))
+	}
+	return f
+}
+
+// WithFlags 使用命令行参数覆盖配置，只有命令行中出现的参数才会覆盖
+func WithFlags(flags *Flags) Option {
+	return func(l *Loader) {
+		l.flags = flags
+	}
+}
+
+// layer 将已设置的参数转换为一层配置
+func (f *Flags) layer() (map[string]any, error) {
+	layer := make(map[string]any)
+	for _, ff := range f.fields {
+		if !ff.set {
+			continue
+		}
+		value, err := parseFieldValue(ff.leaf.fd, ff.raw)
+		if err != nil {
+			return nil, fmt.Errorf("confutil: flag --%s: %w", strings.Join(ff.leaf.path, "."), err)
+		}
+		setPath(layer, ff.leaf.path, value)
+	}
+	return layer, nil
+}
+
+// fieldFlag 实现 flag.Value，记录参数是否出现在命令行中
+type fieldFlag struct {
+	leaf fieldPath
+	raw  string
+	set  bool
+}
+
+func (f *fieldFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.raw
+}
+
+func (f *fieldFlag) Set(s string) error {
+	if _, err := parseFieldValue(f.leaf.fd, s); err != nil {
+		return err
+	}
+	f.raw, f.set = s, true
+	return nil
+}
+
+// IsBoolFlag 布尔字段支持只写参数名，例如 --data.redis.tls
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.leaf.fd.Kind() == protoreflect.BoolKind && !f.leaf.fd.IsList()
+}
+
+// flagUsage 根据字段类型生成参数说明
+func flagUsage(fd protoreflect.FieldDescriptor) string {
+	var usage string
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		usage = "one of " + strings.Join(names, ", ")
+	case protoreflect.MessageKind:
+		usage = "duration, e.g. 1m30s"
+	default:
+		usage = fd.Kind().String()
+	}
+	if fd.IsList() {
+		usage = "comma separated list of " + usage
+	}
+	return "override " + string(fd.FullName()) + " (" + usage + ")"
+}
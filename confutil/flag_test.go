@@ -0,0 +1,84 @@
+package confutil
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+func TestBindFlags(t *testing.T) {
+	c := conf.DefaultBootstrap()
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	flags := BindFlags(fs, c)
+	err := fs.Parse([]string{
+		"--data.database.max_open_connections=50",
+		"--data.database.connection_life_time=2m",
+		"--data.redis.tls",
+		"--log.format", "FORMAT_TEXT",
+	})
+	require.NoError(t, err)
+
+	err = Load(c,
+		WithFiles("testdata/bootstrap.yaml"),
+		WithEnvPrefix("APP"),
+		environ("APP_DATA_DATABASE_MAX_OPEN_CONNECTIONS=20", "APP_DATA_DATABASE_HOST=10.0.0.1"),
+		WithFlags(flags),
+	)
+	require.NoError(t, err)
+
+	db := c.GetData().GetDatabase()
+	assert.Equal(t, int32(50), db.GetMaxOpenConnections(), "flags override env")
+	assert.Equal(t, "10.0.0.1", db.GetHost())
+	assert.Equal(t, 2*time.Minute, db.GetConnectionLifeTime().AsDuration())
+	assert.True(t, c.GetData().GetRedis().GetTls())
+	assert.Equal(t, conf.Log_FORMAT_TEXT, c.GetLog().GetFormat())
+}
+
+func TestBindFlagsInvalid(t *testing.T) {
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	BindFlags(fs, &conf.Bootstrap{})
+	err := fs.Parse([]string{"--data.database.port=abc"})
+	assert.ErrorContains(t, err, "data.database.port")
+
+	assert.NotNil(t, fs.Lookup("server.http.timeout"))
+	assert.Contains(t, fs.Lookup("log.format").Usage, "FORMAT_JSON")
+	assert.Nil(t, fs.Lookup("data.database.params"), "map fields are not bound")
+}
+
+func TestDump(t *testing.T) {
+	c := conf.DefaultBootstrap()
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	flags := BindFlags(fs, c)
+	require.NoError(t, fs.Parse([]string{"--data.database.port=3307"}))
+
+	l := NewLoader(
+		WithFiles("testdata/bootstrap.yaml"),
+		WithEnvPrefix("APP"),
+		environ("APP_DATA_DATABASE_PASSWORD=dump-secret"),
+		WithFlags(flags),
+	)
+	require.NoError(t, l.Load(c))
+
+	origins := l.Origins()
+	assert.Equal(t, Origin{Source: SourceFile, Name: "testdata/bootstrap.yaml"}, origins["data.database.host"])
+	assert.Equal(t, Origin{Source: SourceEnv, Name: "APP_DATA_DATABASE_PASSWORD"}, origins["data.database.password"])
+	assert.Equal(t, Origin{Source: SourceFlag, Name: "--data.database.port"}, origins["data.database.port"])
+	assert.Equal(t, Origin{Source: SourceDefault}, origins["server.grpc.addr"])
+
+	var buf bytes.Buffer
+	require.NoError(t, l.Dump(&buf, c))
+	output := buf.String()
+	assert.Contains(t, output, `data.database.host = "127.0.0.1"  # file:testdata/bootstrap.yaml`+"\n")
+	assert.Contains(t, output, `data.database.password = "******"  # env:APP_DATA_DATABASE_PASSWORD`+"\n")
+	assert.Contains(t, output, `data.database.port = 3307  # flag:--data.database.port`+"\n")
+	assert.Contains(t, output, `server.grpc.addr = "0.0.0.0:9000"  # default`+"\n")
+	assert.NotContains(t, output, "dump-secret")
+}
@@ -0,0 +1,208 @@
+package confutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Option 配置加载选项
+type Option func(l *Loader)
+
+// WithFiles 按顺序加载的配置文件，后面的文件覆盖前面的同名字段，文件不存在时报错
+func WithFiles(paths ...string) Option {
+	return func(l *Loader) {
+		for _, path := range paths {
+			l.files = append(l.files, configFile{path: path})
+		}
+	}
+}
+
+// WithOptionalFiles 可选的配置文件（例如按环境区分的 config.prod.yaml），文件不存在时跳过
+func WithOptionalFiles(paths ...string) Option {
+	return func(l *Loader) {
+		for _, path := range paths {
+			l.files = append(l.files, configFile{path: path, optional: true})
+		}
+	}
+}
+
+// WithEnvPrefix 使用带前缀的环境变量覆盖配置，变量名为 前缀_字段路径 的大写形式，
+// 例如前缀 APP 时 APP_DATA_DATABASE_MAX_OPEN_CONNECTIONS 覆盖 data.database.max_open_connections
+func WithEnvPrefix(prefix string) Option {
+	return func(l *Loader) {
+		l.envPrefix = prefix
+	}
+}
+
+// WithEnviron 自定义环境变量来源，格式同 os.Environ，默认为 os.Environ
+func WithEnviron(environ func() []string) Option {
+	return func(l *Loader) {
+		l.environ = environ
+	}
+}
+
+// WithDiscardUnknown 忽略消息中不存在的字段，默认遇到未知字段时报错，便于发现配置拼写错误
+func WithDiscardUnknown(discard bool) Option {
+	return func(l *Loader) {
+		l.discardUnknown = discard
+	}
+}
+
+// WithValidation 加载后是否按 RegisterRules 注册的规则校验配置，默认开启
+func WithValidation(enabled bool) Option {
+	return func(l *Loader) {
+		l.validate = enabled
+	}
+}
+
+type configFile struct {
+	path     string
+	optional bool
+}
+
+// Loader 配置加载器，将 YAML、JSON、TOML 文件和环境变量按层合并后通过 protojson 解析到任意 proto 消息。
+// 合并顺序（后者覆盖前者）：消息中已有的值（默认值） -> 配置文件（按传入顺序） -> 环境变量 -> 命令行参数，
+// 合并后替换 ${env:...}、${file:...} 等密钥引用，再按注册的规则校验，校验失败时返回 *ValidationError。
+type Loader struct {
+	files           []configFile
+	envPrefix       string
+	environ         func() []string
+	discardUnknown  bool
+	validate        bool
+	secretProviders map[string]SecretProvider
+	flags           *Flags
+
+	mu      sync.Mutex
+	origins map[string]Origin
+}
+
+// NewLoader 创建配置加载器
+func NewLoader(opts ...Option) *Loader {
+	l := &Loader{environ: os.Environ, validate: true}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Load 使用给定选项加载配置到 dst
+func Load(dst proto.Message, opts ...Option) error {
+	return NewLoader(opts...).Load(dst)
+}
+
+// Load 加载配置到 dst，dst 中已有的值作为默认值
+func (l *Loader) Load(dst proto.Message) error {
+	origins := make(map[string]Origin)
+	values, err := l.merge(dst, origins)
+	if err != nil {
+		return err
+	}
+	if _, err = l.resolveSecrets(values, ""); err != nil {
+		return err
+	}
+	if err = l.unmarshal(values, dst); err != nil {
+		return err
+	}
+	if l.validate {
+		if err = Validate(dst); err != nil {
+			return err
+		}
+	}
+	l.mu.Lock()
+	l.origins = origins
+	l.mu.Unlock()
+	return nil
+}
+
+// merge 按层合并默认值、配置文件、环境变量和命令行参数，并记录每个值的来源
+func (l *Loader) merge(dst proto.Message, origins map[string]Origin) (map[string]any, error) {
+	md := dst.ProtoReflect().Descriptor()
+	values, err := messageToMap(dst)
+	if err != nil {
+		return nil, err
+	}
+	recordOrigins(origins, values, nil, func([]string) Origin {
+		return Origin{Source: SourceDefault}
+	})
+	for _, file := range l.files {
+		layer, err := readFile(file.path)
+		if err != nil {
+			if file.optional && os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if layer, err = normalize(layer, md); err != nil {
+			return nil, fmt.Errorf("confutil: %s: %w", file.path, err)
+		}
+		deepMerge(values, layer)
+		recordOrigins(origins, layer, nil, func([]string) Origin {
+			return Origin{Source: SourceFile, Name: file.path}
+		})
+	}
+	if l.envPrefix != "" {
+		layer, err := envLayer(l.environ(), l.envPrefix, md)
+		if err != nil {
+			return nil, err
+		}
+		deepMerge(values, layer)
+		recordOrigins(origins, layer, nil, func(path []string) Origin {
+			return Origin{Source: SourceEnv, Name: envName(l.envPrefix, path)}
+		})
+	}
+	if l.flags != nil {
+		layer, err := l.flags.layer()
+		if err != nil {
+			return nil, err
+		}
+		deepMerge(values, layer)
+		recordOrigins(origins, layer, nil, func(path []string) Origin {
+			return Origin{Source: SourceFlag, Name: "--" + strings.Join(path, ".")}
+		})
+	}
+	return values, nil
+}
+
+func (l *Loader) unmarshal(values map[string]any, dst proto.Message) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("confutil: %w", err)
+	}
+	proto.Reset(dst)
+	if err = (protojson.UnmarshalOptions{DiscardUnknown: l.discardUnknown}).Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("confutil: %w", err)
+	}
+	return nil
+}
+
+// messageToMap 将消息转换为以 proto 字段名为 key 的 map
+func messageToMap(m proto.Message) (map[string]any, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("confutil: %w", err)
+	}
+	values := make(map[string]any)
+	if err = json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("confutil: %w", err)
+	}
+	return values, nil
+}
+
+// deepMerge 将 src 合并到 dst，两边都是对象时递归合并，否则 src 覆盖 dst（列表整体替换）
+func deepMerge(dst, src map[string]any) {
+	for key, value := range src {
+		srcMap, srcOK := value.(map[string]any)
+		dstMap, dstOK := dst[key].(map[string]any)
+		if srcOK && dstOK {
+			deepMerge(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
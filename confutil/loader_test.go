@@ -0,0 +1,143 @@
+package confutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+func environ(kv ...string) Option {
+	return WithEnviron(func() []string { return kv })
+}
+
+func TestLoadYAML(t *testing.T) {
+	var c conf.Data
+	err := Load(&c, WithFiles("testdata/base.yaml"))
+	require.NoError(t, err)
+
+	db := c.GetDatabase()
+	assert.Equal(t, "mysql", db.GetDriver())
+	assert.Equal(t, "127.0.0.1", db.GetHost())
+	assert.Equal(t, int32(3306), db.GetPort())
+	assert.Equal(t, int32(20), db.GetMaxOpenConnections())
+	assert.Equal(t, int32(5), db.GetMaxIdleConnections())
+	assert.Equal(t, 90*time.Second, db.GetConnectionLifeTime().AsDuration())
+	assert.Equal(t, map[string]string{"charset": "utf8mb4"}, db.GetParams())
+}
+
+func TestLoadLayered(t *testing.T) {
+	var c conf.Data
+	err := Load(&c, WithFiles("testdata/base.yaml", "testdata/override.json", "testdata/override.toml"))
+	require.NoError(t, err)
+
+	db := c.GetDatabase()
+	assert.Equal(t, "db.internal", db.GetHost())
+	assert.Equal(t, "app_toml", db.GetDatabase())
+	assert.Equal(t, int32(50), db.GetMaxOpenConnections())
+	assert.Equal(t, int32(5), db.GetMaxIdleConnections())
+	assert.Equal(t, 2*time.Minute, db.GetConnectionLifeTime().AsDuration())
+	assert.Equal(t, conf.Data_Database_TLS_MODE_REQUIRED, db.GetTlsMode())
+	assert.Equal(t, map[string]string{"charset": "utf8mb4", "parseTime": "true"}, db.GetParams())
+}
+
+func TestLoadDefaults(t *testing.T) {
+	c := conf.Data{Database: &conf.Data_Database{
+		Driver:             "mysql",
+		MaxIdleConnections: 2,
+		ConnectionLifeTime: durationpb.New(time.Hour),
+	}}
+	err := Load(&c, WithFiles("testdata/override.json"))
+	require.NoError(t, err)
+
+	db := c.GetDatabase()
+	assert.Equal(t, "mysql", db.GetDriver())
+	assert.Equal(t, int32(2), db.GetMaxIdleConnections())
+	assert.Equal(t, "db.internal", db.GetHost())
+	assert.Equal(t, 500*time.Millisecond, db.GetConnectionLifeTime().AsDuration())
+}
+
+func TestLoadOptionalFiles(t *testing.T) {
+	var c conf.Data
+	err := Load(&c, WithFiles("testdata/base.yaml"), WithOptionalFiles("testdata/missing.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "mysql", c.GetDatabase().GetDriver())
+
+	err = Load(&c, WithFiles("testdata/missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestLoadUnknownField(t *testing.T) {
+	var c conf.Data
+	err := Load(&c, WithFiles("testdata/unknown.yaml"))
+	assert.ErrorContains(t, err, "hots")
+
+	err = Load(&c, WithFiles("testdata/unknown.yaml"), WithDiscardUnknown(true))
+	assert.NoError(t, err)
+}
+
+func TestLoadEnv(t *testing.T) {
+	var c conf.Data
+	err := Load(&c,
+		WithFiles("testdata/base.yaml"),
+		WithEnvPrefix("app"),
+		environ(
+			"APP_DATABASE_HOST=10.0.0.1",
+			"APP_DATABASE_MAX_OPEN_CONNECTIONS=64",
+			"APP_DATABASE_CONNECTION_LIFE_TIME=5m",
+			"APP_DATABASE_TLS_MODE=TLS_MODE_VERIFY_FULL",
+			"OTHER_DATABASE_HOST=ignored",
+		),
+	)
+	require.NoError(t, err)
+
+	db := c.GetDatabase()
+	assert.Equal(t, "10.0.0.1", db.GetHost())
+	assert.Equal(t, int32(64), db.GetMaxOpenConnections())
+	assert.Equal(t, 5*time.Minute, db.GetConnectionLifeTime().AsDuration())
+	assert.Equal(t, conf.Data_Database_TLS_MODE_VERIFY_FULL, db.GetTlsMode())
+	assert.Equal(t, "root", db.GetUser())
+}
+
+func TestLoadEnvInvalid(t *testing.T) {
+	var c conf.Data
+	err := Load(&c, WithEnvPrefix("APP"), environ("APP_DATABASE_PORT=abc"))
+	assert.ErrorContains(t, err, "APP_DATABASE_PORT")
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{90 * time.Second, "90s"},
+		{500 * time.Millisecond, "0.5s"},
+		{-1500 * time.Millisecond, "-1.5s"},
+		{time.Nanosecond, "0.000000001s"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatDuration(tt.in))
+	}
+}
+
+func TestLoadBootstrap(t *testing.T) {
+	c := conf.DefaultBootstrap()
+	err := Load(c, WithFiles("testdata/bootstrap.yaml"))
+	require.NoError(t, err)
+
+	assert.True(t, c.GetApp().IsProduction())
+	assert.Equal(t, "0.0.0.0:8080", c.GetServer().GetHttp().GetAddr())
+	assert.Equal(t, 2*time.Second, c.GetServer().GetHttp().GetTimeout().AsDuration())
+	// 未配置的字段保留默认值
+	assert.Equal(t, 10*time.Second, c.GetServer().GetHttp().GetReadTimeout().AsDuration())
+	assert.Equal(t, "0.0.0.0:9000", c.GetServer().GetGrpc().GetAddr())
+	assert.Equal(t, "redis:6379", c.GetData().GetRedis().GetAddr())
+	assert.Equal(t, 3*time.Second, c.GetData().GetRedis().GetReadTimeout().AsDuration())
+	assert.Equal(t, conf.Log_FORMAT_TEXT, c.GetLog().GetFormat())
+	assert.Equal(t, "stdout", c.GetLog().GetOutput())
+}
@@ -0,0 +1,116 @@
+package confutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+// Source 配置值的来源类型
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Origin 配置值的来源
+type Origin struct {
+	Source Source
+	// Name 来源名称：配置文件路径、环境变量名或命令行参数名，默认值时为空
+	Name string
+}
+
+func (o Origin) String() string {
+	if o.Name == "" {
+		return string(o.Source)
+	}
+	return string(o.Source) + ":" + o.Name
+}
+
+// recordOrigins 记录一层配置中每个叶子值的来源，key 为点分隔的字段路径
+func recordOrigins(origins map[string]Origin, layer map[string]any, prefix []string, origin func(path []string) Origin) {
+	for key, value := range layer {
+		path := append(append([]string(nil), prefix...), key)
+		if m, ok := value.(map[string]any); ok && len(m) > 0 {
+			recordOrigins(origins, m, path, origin)
+			continue
+		}
+		origins[strings.Join(path, ".")] = origin(path)
+	}
+}
+
+// Origins 返回最近一次成功加载时每个配置值的来源，key 为点分隔的字段路径，例如 data.database.host
+func (l *Loader) Origins() map[string]Origin {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return maps.Clone(l.origins)
+}
+
+// lookupOrigin 查找字段路径的来源，列表等整体赋值的字段按最近的上级路径查找
+func lookupOrigin(origins map[string]Origin, key string) Origin {
+	for {
+		if o, ok := origins[key]; ok {
+			return o
+		}
+		i := strings.LastIndexByte(key, '.')
+		if i < 0 {
+			return Origin{Source: SourceDefault}
+		}
+		key = key[:i]
+	}
+}
+
+// Dump 按字段路径逐行输出 m 中的最终配置及其来源，敏感字段和解析出的密钥已脱敏，m 应为本 Loader 加载的配置：
+//
+//	data.database.host = "10.0.0.1"  # env:APP_DATA_DATABASE_HOST
+//	data.database.password = "******"  # file:config.yaml
+func (l *Loader) Dump(w io.Writer, m proto.Message) error {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(conf.Redact(m))
+	if err != nil {
+		return fmt.Errorf("confutil: %w", err)
+	}
+	values := make(map[string]any)
+	if err = json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("confutil: %w", err)
+	}
+	lines := make(map[string]any)
+	flatten(lines, values, "")
+	keys := make([]string, 0, len(lines))
+	for key := range lines {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	origins := l.Origins()
+	for _, key := range keys {
+		value, err := json.Marshal(lines[key])
+		if err != nil {
+			return fmt.Errorf("confutil: %w", err)
+		}
+		if _, err = fmt.Fprintf(w, "%s = %s  # %s\n", key, conf.RedactSecrets(string(value)), lookupOrigin(origins, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func flatten(lines map[string]any, values map[string]any, prefix string) {
+	for key, value := range values {
+		path := joinPath(prefix, key)
+		if m, ok := value.(map[string]any); ok && len(m) > 0 {
+			flatten(lines, m, path)
+			continue
+		}
+		lines[path] = value
+	}
+}
@@ -0,0 +1,133 @@
+package confutil
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/lastares/claymore/generalutil"
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+// secretPattern 匹配 ${provider:ref}，$${...} 为转义，输出字面量 ${...}
+var secretPattern = regexp.MustCompile(`\$?\$\{([A-Za-z][A-Za-z0-9_-]*):([^}]*)\}`)
+
+// SecretProvider 密钥提供方，根据引用返回密钥明文，例如对接 Vault、KMS
+type SecretProvider interface {
+	Resolve(ref string) (string, error)
+}
+
+// SecretProviderFunc 函数形式的 SecretProvider
+type SecretProviderFunc func(ref string) (string, error)
+
+func (f SecretProviderFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// WithSecretProvider 注册密钥提供方，配置值中的 ${name:ref} 会在加载时替换为 provider 返回的值。
+// 内置 env（读取环境变量，来源同 WithEnviron）和 file（读取文件内容并去掉末尾换行）两种提供方，同名时覆盖内置实现
+func WithSecretProvider(name string, provider SecretProvider) Option {
+	return func(l *Loader) {
+		if l.secretProviders == nil {
+			l.secretProviders = make(map[string]SecretProvider)
+		}
+		l.secretProviders[name] = provider
+	}
+}
+
+// provider 返回指定名称的密钥提供方
+func (l *Loader) provider(name string) (SecretProvider, bool) {
+	if p, ok := l.secretProviders[name]; ok {
+		return p, true
+	}
+	switch name {
+	case "env":
+		return SecretProviderFunc(l.lookupEnv), true
+	case "file":
+		return SecretProviderFunc(readSecretFile), true
+	}
+	return nil, false
+}
+
+func (l *Loader) lookupEnv(name string) (string, error) {
+	for _, kv := range l.environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok && key == name {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("environment variable %s is not set", name)
+}
+
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveSecrets 递归替换配置中的密钥引用，解析出的密钥通过 conf.MarkSensitive 登记，
+// 之后打印配置、输出日志时会被脱敏
+func (l *Loader) resolveSecrets(value any, path string) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			resolved, err := l.resolveSecrets(item, joinPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+	case []any:
+		for i, item := range v {
+			resolved, err := l.resolveSecrets(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+	case string:
+		return l.resolveString(v, path)
+	}
+	return value, nil
+}
+
+func (l *Loader) resolveString(s, path string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var resolveErr error
+	result := secretPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		sub := secretPattern.FindStringSubmatch(match)
+		name, ref := sub[1], sub[2]
+		provider, ok := l.provider(name)
+		if !ok {
+			resolveErr = fmt.Errorf("confutil: %s: unknown secret provider %q", path, name)
+			return match
+		}
+		secret, err := provider.Resolve(ref)
+		if err != nil {
+			resolveErr = fmt.Errorf("confutil: %s: resolve ${%s:%s}: %w", path, name, ref, err)
+			return match
+		}
+		conf.MarkSensitive(secret)
+		return secret
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return result, nil
+}
+
+// PrettyPrint 格式化打印配置，proto 消息中的敏感字段以及加载时解析出的密钥会被脱敏，
+// 与 generalutil.PrettyPrintStruct 相同
+func PrettyPrint(obj any) {
+	generalutil.PrettyPrintStruct(obj)
+}
@@ -0,0 +1,90 @@
+package confutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+func TestLoadSecrets(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "db_user")
+	require.NoError(t, os.WriteFile(secretFile, []byte("app_user\n"), 0o600))
+	path := filepath.Join(dir, "config.yaml")
+	writeConfig(t, path, fmt.Sprintf(`database:
+  driver: mysql
+  user: ${file:%s}
+  password: ${env:DB_PASS}
+  source: app_user:${vault:db/password}@tcp(127.0.0.1:3306)/app
+  params:
+    literal: $${env:NOT_RESOLVED}
+`, secretFile))
+
+	vault := SecretProviderFunc(func(ref string) (string, error) {
+		assert.Equal(t, "db/password", ref)
+		return "vault-secret-value", nil
+	})
+	var c conf.Data
+	err := Load(&c,
+		WithFiles(path),
+		WithSecretProvider("vault", vault),
+		environ("DB_PASS=env-secret-value"),
+	)
+	require.NoError(t, err)
+
+	db := c.GetDatabase()
+	assert.Equal(t, "app_user", db.GetUser())
+	assert.Equal(t, "env-secret-value", db.GetPassword())
+	assert.Equal(t, "app_user:vault-secret-value@tcp(127.0.0.1:3306)/app", db.GetSource())
+	assert.Equal(t, "${env:NOT_RESOLVED}", db.GetParams()["literal"])
+
+	// 解析出的密钥在打印时被脱敏
+	assert.True(t, conf.IsSensitive("vault-secret-value"))
+	output := fmt.Sprint(&c)
+	assert.NotContains(t, output, "env-secret-value")
+	assert.NotContains(t, output, "vault-secret-value")
+	assert.NotContains(t, output, "app_user")
+}
+
+func TestLoadSecretsError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	writeConfig(t, path, "database:\n  driver: mysql\n  password: ${env:MISSING}\n")
+	err := Load(&conf.Data{}, WithFiles(path), environ())
+	assert.ErrorContains(t, err, "database.password")
+	assert.ErrorContains(t, err, "MISSING")
+
+	writeConfig(t, path, "database:\n  driver: mysql\n  password: ${vualt:db}\n")
+	err = Load(&conf.Data{}, WithFiles(path))
+	assert.ErrorContains(t, err, `unknown secret provider "vualt"`)
+
+	failing := SecretProviderFunc(func(string) (string, error) { return "", errors.New("denied") })
+	writeConfig(t, path, "database:\n  driver: mysql\n  password: ${vault:db}\n")
+	err = Load(&conf.Data{}, WithFiles(path), WithSecretProvider("vault", failing))
+	assert.ErrorContains(t, err, "denied")
+}
+
+func TestPrettyPrint(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	conf.MarkSensitive("pretty-print-secret")
+	PrettyPrint(&conf.Data_Database{User: "root", Password: "plain-password"})
+	PrettyPrint(struct{ Token string }{Token: "pretty-print-secret"})
+
+	output := buf.String()
+	assert.NotContains(t, output, "plain-password")
+	assert.NotContains(t, output, "pretty-print-secret")
+	assert.Contains(t, output, conf.RedactedValue)
+	assert.Contains(t, output, "root")
+}
@@ -0,0 +1,260 @@
+package confutil
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Violation 一条校验失败信息
+type Violation struct {
+	// Field 字段路径，例如 database.max_idle_connections、servers[0].addr
+	Field   string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Field == "" {
+		return v.Message
+	}
+	return v.Field + ": " + v.Message
+}
+
+// ValidationError 配置校验失败，包含所有违反的规则
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	items := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		items = append(items, v.String())
+	}
+	return "confutil: invalid config: " + strings.Join(items, "; ")
+}
+
+// Rule 消息级别的校验规则，返回的 Violation.Field 为相对当前消息的字段路径
+type Rule func(m protoreflect.Message) []Violation
+
+var (
+	rulesMu sync.RWMutex
+	rules   = make(map[protoreflect.FullName][]Rule)
+)
+
+// RegisterRules 为消息类型注册校验规则，同一类型多次注册时规则累加。
+// 校验时会递归检查嵌套消息、列表和 map 中的消息，因此只需为各自的消息类型注册规则。
+//
+//	confutil.RegisterRules(&conf.Data_Database{},
+//		confutil.Required("driver"),
+//		confutil.Range("port", 0, 65535),
+//	)
+func RegisterRules(m proto.Message, rs ...Rule) {
+	name := m.ProtoReflect().Descriptor().FullName()
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = append(rules[name], rs...)
+}
+
+func rulesFor(name protoreflect.FullName) []Rule {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	return rules[name]
+}
+
+// Validate 按注册的规则校验消息，所有违反的规则一起以 *ValidationError 返回
+func Validate(m proto.Message) error {
+	violations := validateMessage(m.ProtoReflect(), "")
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: violations}
+}
+
+func validateMessage(m protoreflect.Message, prefix string) []Violation {
+	var violations []Violation
+	for _, rule := range rulesFor(m.Descriptor().FullName()) {
+		for _, v := range rule(m) {
+			v.Field = joinPath(prefix, v.Field)
+			violations = append(violations, v)
+		}
+	}
+	m.Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		path := joinPath(prefix, string(fd.Name()))
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Message() == nil {
+				break
+			}
+			value.Map().Range(func(key protoreflect.MapKey, item protoreflect.Value) bool {
+				violations = append(violations, validateMessage(item.Message(), fmt.Sprintf("%s[%v]", path, key.Interface()))...)
+				return true
+			})
+		case fd.IsList():
+			if fd.Message() == nil {
+				break
+			}
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				violations = append(violations, validateMessage(list.Get(i).Message(), fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		case fd.Message() != nil:
+			violations = append(violations, validateMessage(value.Message(), path)...)
+		}
+		return true
+	})
+	return violations
+}
+
+func joinPath(prefix, field string) string {
+	switch {
+	case prefix == "":
+		return field
+	case field == "":
+		return prefix
+	}
+	return prefix + "." + field
+}
+
+// field 按名称查找字段，规则中写错字段名属于编程错误，直接 panic
+func field(m protoreflect.Message, name string) protoreflect.FieldDescriptor {
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		panic(fmt.Sprintf("confutil: message %s has no field %q", m.Descriptor().FullName(), name))
+	}
+	return fd
+}
+
+func violation(name, format string, args ...any) []Violation {
+	return []Violation{{Field: name, Message: fmt.Sprintf(format, args...)}}
+}
+
+// Required 字段必须设置：字符串去掉首尾空白后不能为空，数值不能为 0，消息和列表不能为空
+func Required(name string) Rule {
+	return func(m protoreflect.Message) []Violation {
+		fd := field(m, name)
+		if !m.Has(fd) {
+			return violation(name, "is required")
+		}
+		if fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap() && strings.TrimSpace(m.Get(fd).String()) == "" {
+			return violation(name, "is required")
+		}
+		return nil
+	}
+}
+
+// Range 数值字段的取值范围（闭区间），字段未设置时按 0 校验
+func Range(name string, min, max float64) Rule {
+	return func(m protoreflect.Message) []Violation {
+		fd := field(m, name)
+		n, ok := number(m.Get(fd), fd.Kind())
+		if !ok {
+			panic(fmt.Sprintf("confutil: field %s is not a number", fd.FullName()))
+		}
+		if n < min || n > max {
+			return violation(name, "must be between %v and %v, got %v", min, max, n)
+		}
+		return nil
+	}
+}
+
+// OneOf 字符串字段只能取给定的值之一，区分大小写且不去除空白，字段为空时不校验（配合 Required 使用）
+func OneOf(name string, values ...string) Rule {
+	return func(m protoreflect.Message) []Violation {
+		value := m.Get(field(m, name)).String()
+		if value == "" || slices.Contains(values, value) {
+			return nil
+		}
+		return violation(name, "must be one of [%s], got %q", strings.Join(values, ", "), value)
+	}
+}
+
+// Pattern 字符串字段必须匹配正则表达式，字段为空时不校验
+func Pattern(name string, pattern string) Rule {
+	re := regexp.MustCompile(pattern)
+	return func(m protoreflect.Message) []Violation {
+		value := m.Get(field(m, name)).String()
+		if value == "" || re.MatchString(value) {
+			return nil
+		}
+		return violation(name, "must match %s, got %q", pattern, value)
+	}
+}
+
+// DefinedEnum 枚举字段必须是定义过的值，避免配置中写了数字或新版本才有的枚举值
+func DefinedEnum(name string) Rule {
+	return func(m protoreflect.Message) []Violation {
+		fd := field(m, name)
+		value := m.Get(fd).Enum()
+		if fd.Enum().Values().ByNumber(value) == nil {
+			return violation(name, "unknown enum value %d", value)
+		}
+		return nil
+	}
+}
+
+// DurationRange Duration 字段的取值范围（闭区间），max 为 0 表示不限制上限，字段未设置时不校验
+func DurationRange(name string, min, max time.Duration) Rule {
+	return func(m protoreflect.Message) []Violation {
+		fd := field(m, name)
+		if !m.Has(fd) {
+			return nil
+		}
+		d, ok := m.Get(fd).Message().Interface().(*durationpb.Duration)
+		if !ok {
+			panic(fmt.Sprintf("confutil: field %s is not a google.protobuf.Duration", fd.FullName()))
+		}
+		if err := d.CheckValid(); err != nil {
+			return violation(name, "%v", err)
+		}
+		if value := d.AsDuration(); value < min || (max > 0 && value > max) {
+			if max > 0 {
+				return violation(name, "must be between %v and %v, got %v", min, max, value)
+			}
+			return violation(name, "must be at least %v, got %v", min, value)
+		}
+		return nil
+	}
+}
+
+// LessOrEqual 数值字段 name 不能大于字段 other，other 为 0 时视为不限制
+func LessOrEqual(name, other string) Rule {
+	return func(m protoreflect.Message) []Violation {
+		fd, otherFD := field(m, name), field(m, other)
+		value, _ := number(m.Get(fd), fd.Kind())
+		limit, _ := number(m.Get(otherFD), otherFD.Kind())
+		if limit != 0 && value > limit {
+			return violation(name, "must not exceed %s (%v), got %v", other, limit, value)
+		}
+		return nil
+	}
+}
+
+// Func 自定义规则，适用于跨字段等复杂校验，fn 返回错误时记录为 name 字段的违规
+func Func[T proto.Message](name string, fn func(m T) error) Rule {
+	return func(m protoreflect.Message) []Violation {
+		if err := fn(m.Interface().(T)); err != nil {
+			return violation(name, "%v", err)
+		}
+		return nil
+	}
+}
+
+func number(value protoreflect.Value, kind protoreflect.Kind) (float64, bool) {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return float64(value.Int()), true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return float64(value.Uint()), true
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return value.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,120 @@
+package confutil
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+func fields(err error) []string {
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		return nil
+	}
+	var result []string
+	for _, v := range verr.Violations {
+		result = append(result, v.Field)
+	}
+	return result
+}
+
+func TestValidateConfData(t *testing.T) {
+	valid := &conf.Data{Database: &conf.Data_Database{
+		Driver:             "mysql",
+		MaxOpenConnections: 20,
+		MaxIdleConnections: 10,
+		ConnectionLifeTime: durationpb.New(time.Hour),
+	}}
+	assert.NoError(t, Validate(valid))
+	// driver 必填，且只能是 dbutil.New 支持的 mysql
+	assert.Equal(t, []string{"database.driver"}, fields(Validate(&conf.Data{Database: &conf.Data_Database{}})))
+	assert.Equal(t, []string{"database.driver"}, fields(Validate(&conf.Data{Database: &conf.Data_Database{Driver: "postgres"}})))
+
+	invalid := &conf.Data{Database: &conf.Data_Database{
+		Driver:             " ",
+		MaxOpenConnections: 10,
+		MaxIdleConnections: 20,
+		ConnectionLifeTime: durationpb.New(-time.Second),
+		Port:               70000,
+		TlsMode:            42,
+	}}
+	err := Validate(invalid)
+	require.Error(t, err)
+	assert.ElementsMatch(t, []string{
+		"database.driver",
+		"database.driver",
+		"database.max_idle_connections",
+		"database.connection_life_time",
+		"database.port",
+		"database.tls_mode",
+	}, fields(err))
+	assert.Contains(t, err.Error(), "database.max_idle_connections: must not exceed max_open_connections")
+}
+
+func TestValidateConfApp(t *testing.T) {
+	assert.NoError(t, Validate(&conf.App{Env: "prod"}))
+	assert.NoError(t, Validate(&conf.App{Env: "Production"}))
+	assert.NoError(t, Validate(&conf.App{}))
+
+	err := Validate(&conf.App{Env: "prod "})
+	assert.Equal(t, []string{"env"}, fields(err))
+}
+
+func TestLoadValidation(t *testing.T) {
+	var c conf.Data
+	err := Load(&c, WithEnvPrefix("APP"), environ("APP_DATABASE_MAX_IDLE_CONNECTIONS=5", "APP_DATABASE_MAX_OPEN_CONNECTIONS=2"))
+	require.Error(t, err)
+	assert.ElementsMatch(t, []string{"database.driver", "database.max_idle_connections"}, fields(err))
+
+	err = Load(&c, WithValidation(false), WithEnvPrefix("APP"), environ("APP_DATABASE_MAX_IDLE_CONNECTIONS=5"))
+	assert.NoError(t, err)
+}
+
+func TestRules(t *testing.T) {
+	db := &conf.Data_Database{Driver: "mysql", Host: "localhost"}
+	m := db.ProtoReflect()
+
+	assert.Empty(t, Required("host")(m))
+	assert.Len(t, Required("database")(m), 1)
+	assert.Len(t, Required("connection_life_time")(m), 1)
+
+	assert.Empty(t, Pattern("host", `^[a-z.]+$`)(m))
+	assert.Len(t, Pattern("driver", `^pg`)(m), 1)
+
+	assert.Empty(t, DurationRange("connection_life_time", time.Second, 0)(m), "unset duration is skipped")
+	db.ConnectionLifeTime = durationpb.New(time.Millisecond)
+	assert.Len(t, DurationRange("connection_life_time", time.Second, 0)(m), 1)
+
+	rule := Func("host", func(db *conf.Data_Database) error {
+		if db.GetHost() == "localhost" && db.GetPort() == 0 {
+			return errors.New("port is required for localhost")
+		}
+		return nil
+	})
+	assert.Equal(t, []Violation{{Field: "host", Message: "port is required for localhost"}}, rule(m))
+
+	assert.Panics(t, func() { Required("no_such_field")(m) })
+}
+
+func TestValidateBootstrap(t *testing.T) {
+	assert.NoError(t, Validate(conf.DefaultBootstrap()))
+
+	c := conf.DefaultBootstrap()
+	c.Log.Level = "loud"
+	c.Trace.SampleRatio = 1.5
+	c.Data.Redis.PoolSize = 5
+	c.Data.Redis.MinIdleConns = 10
+	c.Server.Http.ReadTimeout = durationpb.New(-time.Second)
+	assert.ElementsMatch(t, []string{
+		"log.level",
+		"trace.sample_ratio",
+		"data.redis.min_idle_conns",
+		"server.http.read_timeout",
+	}, fields(Validate(c)))
+}
@@ -0,0 +1,246 @@
+package confutil
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"google.golang.org/protobuf/proto"
+)
+
+// WatchOption 配置热加载选项
+type WatchOption func(o *watchOptions)
+
+type watchOptions struct {
+	debounce time.Duration
+	onError  func(err error)
+}
+
+// WithDebounce 文件变化后等待的时间，期间的多次变化只触发一次重新加载，默认 200ms
+func WithDebounce(d time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.debounce = d
+	}
+}
+
+// WithErrorHandler 重新加载失败时的回调，此时继续使用上一次加载成功的配置，默认输出到标准日志
+func WithErrorHandler(fn func(err error)) WatchOption {
+	return func(o *watchOptions) {
+		o.onError = fn
+	}
+}
+
+type subscriber[T proto.Message] struct {
+	id     uint64
+	notify func(old, new T)
+}
+
+// Watcher 配置热加载，监听配置文件变化后重新加载并原子替换当前配置。
+// T 为配置消息的指针类型，例如 *conf.Data。
+//
+//	w, err := confutil.NewWatcher(loader, &conf.Data{})
+//	confutil.Subscribe(w, (*conf.Data).GetDatabase, func(old, new *conf.Data_Database) {
+//		sqlDB.SetMaxOpenConns(int(new.GetMaxOpenConnections()))
+//	})
+//	go w.Watch(ctx)
+type Watcher[T proto.Message] struct {
+	loader   *Loader
+	defaults T
+	watchOptions
+
+	// mu 保证重新加载和回调按顺序执行
+	mu      sync.Mutex
+	current atomic.Value
+	lastErr atomic.Value
+
+	subMu  sync.Mutex
+	subs   []subscriber[T]
+	nextID uint64
+}
+
+// NewWatcher 创建配置热加载器并立即加载一次配置，defaults 为每次加载时的默认值，不会被修改
+func NewWatcher[T proto.Message](loader *Loader, defaults T, opts ...WatchOption) (*Watcher[T], error) {
+	w := &Watcher[T]{
+		loader:       loader,
+		defaults:     defaults,
+		watchOptions: watchOptions{debounce: 200 * time.Millisecond},
+	}
+	for _, opt := range opts {
+		opt(&w.watchOptions)
+	}
+	if w.onError == nil {
+		w.onError = func(err error) {
+			log.Printf("confutil: reload config: %v", err)
+		}
+	}
+	cfg, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	w.current.Store(cfg)
+	return w, nil
+}
+
+// Current 返回当前配置，返回的消息在多个协程间共享，不要修改
+func (w *Watcher[T]) Current() T {
+	return w.current.Load().(T)
+}
+
+// LastError 返回最近一次重新加载的错误，加载成功后清空
+func (w *Watcher[T]) LastError() error {
+	if e, ok := w.lastErr.Load().(errorValue); ok {
+		return e.err
+	}
+	return nil
+}
+
+// errorValue 包装 error 使 atomic.Value 中始终存储同一类型
+type errorValue struct {
+	err error
+}
+
+func (w *Watcher[T]) load() (T, error) {
+	cfg := proto.Clone(w.defaults).(T)
+	if err := w.loader.Load(cfg); err != nil {
+		var zero T
+		return zero, err
+	}
+	return cfg, nil
+}
+
+// Reload 立即重新加载配置，成功时替换当前配置并通知订阅者，失败时保留当前配置。
+// 订阅回调在 Reload 中同步执行，回调中不要再调用 Reload
+func (w *Watcher[T]) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	next, err := w.load()
+	if err != nil {
+		w.lastErr.Store(errorValue{err: err})
+		w.onError(err)
+		return err
+	}
+	w.lastErr.Store(errorValue{})
+	old := w.Current()
+	w.current.Store(next)
+
+	w.subMu.Lock()
+	subs := append([]subscriber[T](nil), w.subs...)
+	w.subMu.Unlock()
+	for _, sub := range subs {
+		sub.notify(old, next)
+	}
+	return nil
+}
+
+// Watch 监听配置文件变化并自动重新加载，阻塞直到 ctx 结束。
+// 监听的是文件所在目录，编辑器保存时替换文件、Kubernetes ConfigMap 更新符号链接等情况都能感知
+func (w *Watcher[T]) Watch(ctx context.Context) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("confutil: %w", err)
+	}
+	defer fw.Close()
+
+	files := make(map[string]bool, len(w.loader.files))
+	dirs := make(map[string]bool)
+	for _, file := range w.loader.files {
+		path, err := filepath.Abs(file.path)
+		if err != nil {
+			return fmt.Errorf("confutil: %w", err)
+		}
+		files[path] = true
+		dir := filepath.Dir(path)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		if err = fw.Add(dir); err != nil && !(file.optional && os.IsNotExist(err)) {
+			return fmt.Errorf("confutil: watch %s: %w", dir, err)
+		}
+	}
+
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+			if w.affects(files, event) {
+				timer.Reset(w.debounce)
+			}
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			w.onError(err)
+		case <-timer.C:
+			_ = w.Reload()
+		}
+	}
+}
+
+// affects 判断事件是否与配置文件有关，ConfigMap 更新时变化的是目录中以 .. 开头的隐藏文件
+func (w *Watcher[T]) affects(files map[string]bool, event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	path, err := filepath.Abs(event.Name)
+	if err != nil {
+		return false
+	}
+	if files[path] {
+		return true
+	}
+	base := filepath.Base(path)
+	return len(base) > 2 && base[:2] == ".." && event.Op.Has(fsnotify.Create)
+}
+
+// Subscribe 订阅配置中某一部分的变化，selector 从配置中取出关心的部分，
+// 仅当这部分在重新加载前后不相等时才以新旧值回调 fn，返回取消订阅的函数
+func Subscribe[T proto.Message, S any](w *Watcher[T], selector func(T) S, fn func(old, new S)) (cancel func()) {
+	w.subMu.Lock()
+	defer w.subMu.Unlock()
+	w.nextID++
+	id := w.nextID
+	w.subs = append(w.subs, subscriber[T]{
+		id: id,
+		notify: func(old, new T) {
+			oldValue, newValue := selector(old), selector(new)
+			if !equal(oldValue, newValue) {
+				fn(oldValue, newValue)
+			}
+		},
+	})
+	return func() {
+		w.subMu.Lock()
+		defer w.subMu.Unlock()
+		for i, sub := range w.subs {
+			if sub.id == id {
+				w.subs = append(w.subs[:i:i], w.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// equal proto 消息使用 proto.Equal 比较，其他类型使用 reflect.DeepEqual
+func equal(a, b any) bool {
+	if ma, ok := a.(proto.Message); ok {
+		if mb, ok := b.(proto.Message); ok {
+			return proto.Equal(ma, mb)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
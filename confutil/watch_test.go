@@ -0,0 +1,146 @@
+package confutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestWatcherReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "database:\n  driver: mysql\n  max_open_connections: 10\n")
+
+	w, err := NewWatcher(NewLoader(WithFiles(path)), &conf.Data{}, WithErrorHandler(func(error) {}))
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), w.Current().GetDatabase().GetMaxOpenConnections())
+
+	var (
+		calls   int
+		oldOpen int32
+		newOpen int32
+		hostHit int
+	)
+	Subscribe(w, func(c *conf.Data) int32 { return c.GetDatabase().GetMaxOpenConnections() }, func(old, new int32) {
+		calls++
+		oldOpen, newOpen = old, new
+	})
+	cancel := Subscribe(w, func(c *conf.Data) string { return c.GetDatabase().GetHost() }, func(old, new string) {
+		hostHit++
+	})
+
+	writeConfig(t, path, "database:\n  driver: mysql\n  max_open_connections: 30\n")
+	require.NoError(t, w.Reload())
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, int32(10), oldOpen)
+	assert.Equal(t, int32(30), newOpen)
+	assert.Equal(t, 0, hostHit, "unchanged subtree should not be notified")
+
+	// 配置未变化时不回调
+	require.NoError(t, w.Reload())
+	assert.Equal(t, 1, calls)
+
+	cancel()
+	writeConfig(t, path, "database:\n  driver: mysql\n  host: db2\n  max_open_connections: 30\n")
+	require.NoError(t, w.Reload())
+	assert.Equal(t, 0, hostHit)
+}
+
+func TestWatcherKeepsLastGoodConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "database:\n  driver: mysql\n  max_open_connections: 10\n")
+
+	var reported error
+	w, err := NewWatcher(NewLoader(WithFiles(path)), &conf.Data{}, WithErrorHandler(func(err error) {
+		reported = err
+	}))
+	require.NoError(t, err)
+
+	writeConfig(t, path, "database:\n  driver: mysql\n  max_open_connections: [\n")
+	assert.Error(t, w.Reload())
+	assert.Error(t, reported)
+	assert.Equal(t, reported, w.LastError())
+	assert.Equal(t, int32(10), w.Current().GetDatabase().GetMaxOpenConnections())
+
+	writeConfig(t, path, "database:\n  driver: mysql\n  max_open_connections: 20\n")
+	require.NoError(t, w.Reload())
+	assert.NoError(t, w.LastError())
+	assert.Equal(t, int32(20), w.Current().GetDatabase().GetMaxOpenConnections())
+}
+
+func TestWatcherProtoSubtree(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, `{"database": {"driver": "mysql", "host": "a"}}`)
+
+	w, err := NewWatcher(NewLoader(WithFiles(path)), &conf.Data{})
+	require.NoError(t, err)
+
+	var got *conf.Data_Database
+	Subscribe(w, (*conf.Data).GetDatabase, func(old, new *conf.Data_Database) {
+		got = new
+	})
+	require.NoError(t, w.Reload())
+	assert.Nil(t, got)
+
+	writeConfig(t, path, `{"database": {"driver": "mysql", "host": "b"}}`)
+	require.NoError(t, w.Reload())
+	require.NotNil(t, got)
+	assert.Equal(t, "b", got.GetHost())
+}
+
+func TestWatcherWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "database:\n  driver: mysql\n  max_open_connections: 10\n")
+
+	w, err := NewWatcher(NewLoader(WithFiles(path)), &conf.Data{}, WithDebounce(20*time.Millisecond))
+	require.NoError(t, err)
+
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	changed := make(chan int32, 10)
+	Subscribe(w, func(c *conf.Data) int32 { return c.GetDatabase().GetMaxOpenConnections() }, func(old, new int32) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		changed <- new
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Watch(ctx) }()
+	// 等待监听生效
+	time.Sleep(50 * time.Millisecond)
+
+	// 短时间内的多次写入只触发一次重新加载
+	writeConfig(t, path, "database:\n  driver: mysql\n  max_open_connections: 20\n")
+	writeConfig(t, path, "database:\n  driver: mysql\n  max_open_connections: 40\n")
+
+	select {
+	case n := <-changed:
+		assert.Equal(t, int32(40), n)
+	case <-time.After(2 * time.Second):
+		t.Fatal("config change not observed")
+	}
+	time.Sleep(60 * time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, 1, calls)
+	mu.Unlock()
+	assert.Equal(t, int32(40), w.Current().GetDatabase().GetMaxOpenConnections())
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
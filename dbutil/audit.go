@@ -0,0 +1,362 @@
+package dbutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+
+	// AuditMask 敏感字段脱敏后的值
+	AuditMask = "******"
+
+	auditBeforeKey = "dbutil:audit_before"
+)
+
+type actorKey struct{}
+
+// WithActor 将操作人写入 context，审计记录会从中读取操作人
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext 从 context 中读取操作人，不存在时返回空字符串
+func ActorFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// Auditable 需要审计的模型实现该接口即可开启审计（按表开启）。
+// AuditMaskedFields 返回需要脱敏的列名，审计记录中这些列的值会被替换为 AuditMask。
+type Auditable interface {
+	AuditMaskedFields() []string
+}
+
+// FieldChange 单个字段的变更
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// AuditRecord 审计记录，一行数据的一次变更对应一条记录
+type AuditRecord struct {
+	ID        uint64    `gorm:"primaryKey"`
+	Table     string    `gorm:"column:table_name;size:64;index:idx_audit_table_record"`
+	RecordID  string    `gorm:"size:128;index:idx_audit_table_record"`
+	Action    string    `gorm:"size:16"`
+	Actor     string    `gorm:"size:128"`
+	Changes   string    `gorm:"type:text"` // []FieldChange 的 JSON
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// TableName 审计表默认表名
+func (AuditRecord) TableName() string {
+	return "audit_records"
+}
+
+// FieldChanges 解析变更明细
+func (r *AuditRecord) FieldChanges() ([]FieldChange, error) {
+	var changes []FieldChange
+	if r.Changes == "" {
+		return changes, nil
+	}
+	err := json.Unmarshal([]byte(r.Changes), &changes)
+	return changes, err
+}
+
+// AuditSink 审计记录的写入目标。
+// db 为触发变更的 gorm 实例（在事务中时即为事务本身），可用于在同一事务中落库。
+type AuditSink interface {
+	Write(db *gorm.DB, records []*AuditRecord) error
+}
+
+// AuditSinkFunc 函数形式的 AuditSink
+type AuditSinkFunc func(db *gorm.DB, records []*AuditRecord) error
+
+func (f AuditSinkFunc) Write(db *gorm.DB, records []*AuditRecord) error {
+	return f(db, records)
+}
+
+// DBAuditSink 将审计记录写入数据库的审计表，与业务变更处于同一事务
+type DBAuditSink struct {
+	// Table 审计表名，为空时使用 audit_records
+	Table string
+}
+
+func (s DBAuditSink) Write(db *gorm.DB, records []*AuditRecord) error {
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	if s.Table != "" {
+		tx = tx.Table(s.Table)
+	}
+	return tx.Create(records).Error
+}
+
+// RegisterAudit 注册审计回调，对实现了 Auditable 的模型记录 create/update/delete 前后的字段差异。
+// 写入审计记录失败时会将错误写入当前语句，事务中执行时整个事务将回滚。
+// 注意：只有通过模型（Model/Create/Save/Delete 传入结构体）执行的操作才能识别出表对应的模型。
+func RegisterAudit(db *gorm.DB, sink AuditSink) error {
+	a := &auditor{sink: sink}
+	callback := db.Callback()
+	if err := callback.Create().After("gorm:create").Register("dbutil:audit_create", a.afterCreate); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("dbutil:audit_before_update", a.before); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("dbutil:audit_update", a.afterUpdate); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("dbutil:audit_before_delete", a.before); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:delete").Register("dbutil:audit_delete", a.afterDelete)
+}
+
+type auditor struct {
+	sink AuditSink
+}
+
+// before 记录变更前的数据快照
+func (a *auditor) before(db *gorm.DB) {
+	if _, ok := auditable(db.Statement); !ok || db.Error != nil {
+		return
+	}
+	rows, err := a.snapshot(db, auditConditions(db.Statement))
+	if err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	db.InstanceSet(auditBeforeKey, rows)
+}
+
+func (a *auditor) afterCreate(db *gorm.DB) {
+	masked, ok := auditable(db.Statement)
+	if !ok || db.Error != nil || db.Statement.RowsAffected == 0 {
+		return
+	}
+	var records []*AuditRecord
+	for _, row := range modelRows(db.Statement) {
+		records = append(records, a.newRecord(db, AuditActionCreate, row, nil, row, masked))
+	}
+	a.write(db, records)
+}
+
+func (a *auditor) afterUpdate(db *gorm.DB) {
+	masked, ok := auditable(db.Statement)
+	if !ok || db.Error != nil || db.Statement.RowsAffected == 0 {
+		return
+	}
+	before := beforeRows(db)
+	if len(before) == 0 {
+		return
+	}
+	after, err := a.snapshot(db, []clause.Expression{primaryKeyIn(db.Statement.Schema, before)})
+	if err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	afterByID := make(map[string]map[string]any, len(after))
+	for _, row := range after {
+		afterByID[recordID(db.Statement.Schema, row)] = row
+	}
+	var records []*AuditRecord
+	for _, old := range before {
+		row, ok := afterByID[recordID(db.Statement.Schema, old)]
+		if !ok {
+			continue
+		}
+		if record := a.newRecord(db, AuditActionUpdate, row, old, row, masked); record.Changes != "[]" {
+			records = append(records, record)
+		}
+	}
+	a.write(db, records)
+}
+
+func (a *auditor) afterDelete(db *gorm.DB) {
+	masked, ok := auditable(db.Statement)
+	if !ok || db.Error != nil || db.Statement.RowsAffected == 0 {
+		return
+	}
+	var records []*AuditRecord
+	for _, old := range beforeRows(db) {
+		records = append(records, a.newRecord(db, AuditActionDelete, old, old, nil, masked))
+	}
+	a.write(db, records)
+}
+
+func (a *auditor) write(db *gorm.DB, records []*AuditRecord) {
+	if len(records) == 0 {
+		return
+	}
+	if err := a.sink.Write(db, records); err != nil {
+		_ = db.AddError(fmt.Errorf("dbutil: write audit records: %w", err))
+	}
+}
+
+func (a *auditor) newRecord(db *gorm.DB, action string, row, old, cur map[string]any, masked []string) *AuditRecord {
+	changes, _ := json.Marshal(diffFields(db.Statement.Schema, old, cur, masked))
+	return &AuditRecord{
+		Table:     db.Statement.Table,
+		RecordID:  recordID(db.Statement.Schema, row),
+		Action:    action,
+		Actor:     ActorFromContext(db.Statement.Context),
+		Changes:   string(changes),
+		CreatedAt: time.Now(),
+	}
+}
+
+// snapshot 按条件查询当前数据
+func (a *auditor) snapshot(db *gorm.DB, exprs []clause.Expression) ([]map[string]any, error) {
+	stmt := db.Statement
+	if len(exprs) == 0 && !stmt.AllowGlobalUpdate {
+		return nil, nil
+	}
+	if column := softDeleteColumn(stmt.Schema); column != "" && !stmt.Unscoped {
+		exprs = append(exprs, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: nil})
+	}
+	var rows []map[string]any
+	err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).
+		Table(stmt.Table).Clauses(clause.Where{Exprs: exprs}).Find(&rows).Error
+	return rows, err
+}
+
+// auditable 判断语句对应的模型是否开启了审计，返回需要脱敏的字段
+func auditable(stmt *gorm.Statement) ([]string, bool) {
+	if stmt.Schema == nil {
+		return nil, false
+	}
+	if model, ok := reflect.New(stmt.Schema.ModelType).Interface().(Auditable); ok {
+		return model.AuditMaskedFields(), true
+	}
+	return nil, false
+}
+
+// auditConditions 复制语句中的查询条件，并补充模型上的主键条件
+func auditConditions(stmt *gorm.Statement) []clause.Expression {
+	var exprs []clause.Expression
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			exprs = append(exprs, where.Exprs...)
+		}
+	}
+	if stmt.ReflectValue.IsValid() && len(stmt.Schema.PrimaryFields) > 0 {
+		_, values := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		if len(values) > 0 {
+			column, queryValues := schema.ToQueryValues(clause.CurrentTable, stmt.Schema.PrimaryFieldDBNames, values)
+			exprs = append(exprs, clause.IN{Column: column, Values: queryValues})
+		}
+	}
+	return exprs
+}
+
+// primaryKeyIn 按快照中的主键构造查询条件
+func primaryKeyIn(sch *schema.Schema, rows []map[string]any) clause.Expression {
+	values := make([][]any, 0, len(rows))
+	for _, row := range rows {
+		value := make([]any, 0, len(sch.PrimaryFieldDBNames))
+		for _, name := range sch.PrimaryFieldDBNames {
+			value = append(value, row[name])
+		}
+		values = append(values, value)
+	}
+	column, queryValues := schema.ToQueryValues(clause.CurrentTable, sch.PrimaryFieldDBNames, values)
+	return clause.IN{Column: column, Values: queryValues}
+}
+
+// modelRows 将语句中的模型（结构体或切片）转换为 列名 -> 值
+func modelRows(stmt *gorm.Statement) []map[string]any {
+	var rows []map[string]any
+	toRow := func(rv reflect.Value) {
+		row := make(map[string]any, len(stmt.Schema.DBNames))
+		for _, name := range stmt.Schema.DBNames {
+			value, _ := stmt.Schema.FieldsByDBName[name].ValueOf(stmt.Context, rv)
+			row[name] = value
+		}
+		rows = append(rows, row)
+	}
+	switch rv := reflect.Indirect(stmt.ReflectValue); rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			toRow(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		toRow(rv)
+	}
+	return rows
+}
+
+func beforeRows(db *gorm.DB) []map[string]any {
+	value, ok := db.InstanceGet(auditBeforeKey)
+	if !ok {
+		return nil
+	}
+	rows, _ := value.([]map[string]any)
+	return rows
+}
+
+// recordID 主键值，联合主键以逗号分隔
+func recordID(sch *schema.Schema, row map[string]any) string {
+	ids := make([]string, 0, len(sch.PrimaryFieldDBNames))
+	for _, name := range sch.PrimaryFieldDBNames {
+		ids = append(ids, fmt.Sprint(normalizeValue(row[name])))
+	}
+	return strings.Join(ids, ",")
+}
+
+// diffFields 计算字段级差异，old 或 cur 为 nil 时表示新增或删除，返回全部字段
+func diffFields(sch *schema.Schema, old, cur map[string]any, masked []string) []FieldChange {
+	changes := make([]FieldChange, 0)
+	for _, name := range sch.DBNames {
+		oldValue, newValue := normalizeValue(old[name]), normalizeValue(cur[name])
+		if old != nil && cur != nil && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if slices.Contains(masked, name) {
+			if old != nil {
+				oldValue = AuditMask
+			}
+			if cur != nil {
+				newValue = AuditMask
+			}
+		}
+		changes = append(changes, FieldChange{Field: name, Old: oldValue, New: newValue})
+	}
+	return changes
+}
+
+// normalizeValue 统一驱动返回值与模型字段值的表示，便于比较
+func normalizeValue(value any) any {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC()
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return v.UTC()
+	case gorm.DeletedAt:
+		if !v.Valid {
+			return nil
+		}
+		return v.Time.UTC()
+	}
+	return value
+}
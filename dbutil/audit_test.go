@@ -0,0 +1,113 @@
+package dbutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/lastares/claymore/dbutil/dbtest"
+)
+
+type member struct {
+	ID        int64
+	Name      string
+	Password  string
+	DeletedAt gorm.DeletedAt
+}
+
+func (member) AuditMaskedFields() []string {
+	return []string{"password"}
+}
+
+type visitLog struct {
+	ID   int64
+	Path string
+}
+
+func findChange(changes []FieldChange, field string) (FieldChange, bool) {
+	for _, change := range changes {
+		if change.Field == field {
+			return change, true
+		}
+	}
+	return FieldChange{}, false
+}
+
+func TestRegisterAudit(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&member{}, &visitLog{}, &AuditRecord{}))
+	assert.NoError(t, RegisterAudit(db, DBAuditSink{}))
+	ctx := WithActor(context.Background(), "alice")
+	tx := db.WithContext(ctx)
+
+	m := &member{Name: "bob", Password: "secret"}
+	assert.NoError(t, tx.Create(m).Error)
+	assert.NoError(t, tx.Create(&visitLog{Path: "/"}).Error)
+	assert.NoError(t, tx.Model(m).Updates(map[string]any{"name": "bobby", "password": "secret2"}).Error)
+	assert.NoError(t, tx.Model(m).Update("name", "bobby").Error) // 无变化，不产生审计记录
+	assert.NoError(t, tx.Delete(m).Error)
+
+	var records []AuditRecord
+	assert.NoError(t, db.Order("id").Find(&records).Error)
+	assert.Len(t, records, 3)
+	for _, record := range records {
+		assert.Equal(t, "members", record.Table)
+		assert.Equal(t, "alice", record.Actor)
+	}
+	assert.Equal(t, []string{AuditActionCreate, AuditActionUpdate, AuditActionDelete},
+		[]string{records[0].Action, records[1].Action, records[2].Action})
+
+	changes, err := records[1].FieldChanges()
+	assert.NoError(t, err)
+	assert.Len(t, changes, 2)
+	name, ok := findChange(changes, "name")
+	assert.True(t, ok)
+	assert.Equal(t, "bob", name.Old)
+	assert.Equal(t, "bobby", name.New)
+	password, ok := findChange(changes, "password")
+	assert.True(t, ok)
+	assert.Equal(t, AuditMask, password.Old)
+	assert.Equal(t, AuditMask, password.New)
+
+	changes, err = records[0].FieldChanges()
+	assert.NoError(t, err)
+	password, _ = findChange(changes, "password")
+	assert.Nil(t, password.Old)
+	assert.Equal(t, AuditMask, password.New)
+}
+
+func TestRegisterAudit_BatchUpdate(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&member{}))
+	var records []*AuditRecord
+	sink := AuditSinkFunc(func(db *gorm.DB, batch []*AuditRecord) error {
+		records = append(records, batch...)
+		return nil
+	})
+	assert.NoError(t, RegisterAudit(db, sink))
+	assert.NoError(t, db.Create([]*member{{Name: "a"}, {Name: "b"}, {Name: "c"}}).Error)
+	records = nil
+
+	assert.NoError(t, db.Model(&member{}).Where("name IN ?", []string{"a", "b"}).Update("password", "x").Error)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "1", records[0].RecordID)
+	assert.Equal(t, "2", records[1].RecordID)
+	assert.Equal(t, AuditActionUpdate, records[0].Action)
+}
+
+func TestRegisterAudit_SinkErrorRollback(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&member{}))
+	sinkErr := errors.New("sink unavailable")
+	assert.NoError(t, RegisterAudit(db, AuditSinkFunc(func(*gorm.DB, []*AuditRecord) error {
+		return sinkErr
+	})))
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&member{Name: "a"}).Error
+	})
+	assert.ErrorIs(t, err, sinkErr)
+
+	var count int64
+	assert.NoError(t, db.Model(&member{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
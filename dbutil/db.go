@@ -3,7 +3,6 @@ package dbutil
 import (
 	"log"
 	"os"
-	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -13,9 +12,13 @@ import (
 )
 
 func New(dbConfig *conf.Data_Database, gormConfig gorm.Config) (*gorm.DB, error) {
+	dsn, err := BuildDSN(dbConfig)
+	if err != nil {
+		return nil, err
+	}
 	mysqlConfig := InitConfig(
 		WithDriver(dbConfig.Driver),
-		WithDSN(dbConfig.Source),
+		WithDSN(dsn),
 		DefaultStringSize(256), // 为字符串(string)字段设置大小。默认情况下，对于没有大小、没有主键、没有定义索引且没有默认值的字段，将使用db类型“longext”
 
 		DisableDatetimePrecision(true),  // 禁用日期时间精度支持。但是这在MySQL 5.6之前不支持
@@ -48,18 +51,19 @@ func New(dbConfig *conf.Data_Database, gormConfig gorm.Config) (*gorm.DB, error)
 //	返回一个gorm.Config对象，用于配置GORM的行为。
 func GormConfig(app *conf.App) gorm.Config {
 	// 根据应用环境设置日志模式，默认为警告级别
+	defaults := app.Defaults()
 	logMode := logger.Warn
-	// 如果是开发环境，切换到信息级别日志，记录所有SQL执行
-	if app.IsDevelopment() {
+	// 环境要求记录 SQL 时（默认为开发环境）切换到信息级别日志，记录所有SQL执行
+	if defaults.LogSQL {
 		logMode = logger.Info
 	}
 	// 创建一个新的logger实例，配置GORM日志行为
 	newLogger := logger.New(
 		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer，将日志输出到标准输出
 		logger.Config{
-			SlowThreshold:             1 * time.Second, // 慢查询阈值设置为1秒
-			LogLevel:                  logMode,         // 设置日志级别
-			IgnoreRecordNotFoundError: true,            // 忽略记录未找到错误
+			SlowThreshold:             defaults.SlowQueryThreshold, // 慢查询阈值，按环境默认值设置
+			LogLevel:                  logMode,                     // 设置日志级别
+			IgnoreRecordNotFoundError: true,                        // 忽略记录未找到错误
 		},
 	)
 	// 返回配置的GORM配置对象
@@ -1,6 +1,8 @@
 package dbutil
 
 import (
+	"errors"
+	"syscall"
 	"testing"
 
 	"google.golang.org/protobuf/types/known/durationpb"
@@ -26,8 +28,12 @@ func TestNewDB(t *testing.T) {
 	}
 	gormConfig := GormConfig(app)
 	gorm, err := New(databaseConf, gormConfig)
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		// 依赖本地 MySQL，未启动时跳过，其他错误仍视为失败；不依赖外部数据库的仓储测试请使用 dbtest
+		t.Skipf("NewDB error: %v", err)
+	}
 	if err != nil {
-		t.Errorf("NewDB error: %v", err)
+		t.Fatalf("NewDB error: %v", err)
 	}
 	type User struct {
 		ID   int32
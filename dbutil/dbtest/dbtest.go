@@ -0,0 +1,247 @@
+// Package dbtest 基于内存 SQLite 的 gorm 仓储层测试工具：
+// 建表、加载 YAML/JSON 数据夹具、每个测试在自动回滚的事务中执行，以及行数据断言。
+//
+//	func TestUserRepo(t *testing.T) {
+//		db := dbtest.New(t, dbtest.WithModels(&User{}), dbtest.WithFixtures("testdata/users.yaml"))
+//		tx := dbtest.Begin(t, db)
+//		repo := NewUserRepo(tx)
+//		...
+//		dbtest.AssertRow(t, tx, "users", map[string]any{"id": 1}, map[string]any{"name": "bob"})
+//	}
+package dbtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// seq 保证同名测试（例如 -count=2）也使用不同的内存数据库
+var seq atomic.Int64
+
+// Option 测试数据库配置
+type Option func(o *options)
+
+type options struct {
+	models     []any
+	fixtures   []string
+	gormConfig *gorm.Config
+}
+
+// WithModels 需要自动建表的模型
+func WithModels(models ...any) Option {
+	return func(o *options) {
+		o.models = append(o.models, models...)
+	}
+}
+
+// WithFixtures 建表后加载的数据夹具文件
+func WithFixtures(files ...string) Option {
+	return func(o *options) {
+		o.fixtures = append(o.fixtures, files...)
+	}
+}
+
+// WithGormConfig 自定义 gorm 配置，默认关闭日志
+func WithGormConfig(gormConfig *gorm.Config) Option {
+	return func(o *options) {
+		o.gormConfig = gormConfig
+	}
+}
+
+// New 创建一个独立的内存 SQLite 数据库，测试结束时自动关闭。
+// 数据库只使用一个连接，调用 Begin 后请只通过返回的事务访问数据库。
+func New(t testing.TB, opts ...Option) *gorm.DB {
+	t.Helper()
+	o := &options{gormConfig: &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	dsn := fmt.Sprintf("file:%s_%d?mode=memory&cache=shared&_fk=1", name, seq.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), o.gormConfig)
+	if err != nil {
+		t.Fatalf("dbtest: open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("dbtest: open sqlite: %v", err)
+	}
+	// 内存数据库并发写入时容易出现 database table is locked，使用单连接
+	sqlDB.SetMaxOpenConns(1)
+	// 共享缓存的内存数据库在最后一个连接关闭时销毁，连接池丢弃连接（例如查询中途 ctx 取消）后
+	// 新连接看到的是空库，另外保持一个连接直到测试结束
+	keeper, err := gorm.Open(sqlite.Open(dsn), o.gormConfig)
+	if err != nil {
+		t.Fatalf("dbtest: open sqlite: %v", err)
+	}
+	keeperDB, err := keeper.DB()
+	if err != nil {
+		t.Fatalf("dbtest: open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = sqlDB.Close()
+		_ = keeperDB.Close()
+	})
+	if len(o.models) > 0 {
+		if err = db.AutoMigrate(o.models...); err != nil {
+			t.Fatalf("dbtest: migrate: %v", err)
+		}
+	}
+	if err = LoadFixtures(db, o.fixtures...); err != nil {
+		t.Fatalf("dbtest: %v", err)
+	}
+	return db
+}
+
+// Begin 开启事务，测试结束时自动回滚，使各测试之间的数据互不影响
+func Begin(t testing.TB, db *gorm.DB) *gorm.DB {
+	t.Helper()
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatalf("dbtest: begin: %v", tx.Error)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+	})
+	return tx
+}
+
+// LoadFixtures 加载数据夹具文件，支持 .yaml/.yml/.json，格式为 表名 -> 行列表:
+//
+//	users:
+//	  - id: 1
+//	    name: bob
+//	orders:
+//	  - id: 1
+//	    user_id: 1
+//
+// 表按文件中出现的顺序插入，有外键依赖时被依赖的表应写在前面
+func LoadFixtures(db *gorm.DB, files ...string) error {
+	for _, file := range files {
+		switch ext := strings.ToLower(filepath.Ext(file)); ext {
+		case ".yaml", ".yml", ".json":
+		default:
+			return fmt.Errorf("load fixture %s: unsupported format %q", file, ext)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("load fixture %s: %w", file, err)
+		}
+		if err = LoadFixtureData(db, data); err != nil {
+			return fmt.Errorf("load fixture %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// LoadFixtureData 加载 YAML 或 JSON 格式的数据夹具内容（JSON 是 YAML 的子集）
+func LoadFixtureData(db *gorm.DB, data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("fixture root must be a mapping of table name to rows")
+	}
+	// 使用 yaml.Node 遍历以保持表在文件中的顺序
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		table := root.Content[i].Value
+		var rows []map[string]any
+		if err := root.Content[i+1].Decode(&rows); err != nil {
+			return fmt.Errorf("table %s: %w", table, err)
+		}
+		for _, row := range rows {
+			if err := db.Table(table).Create(row).Error; err != nil {
+				return fmt.Errorf("table %s: %w", table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// AssertCount 断言表中满足条件的行数，query 为空时统计全表
+func AssertCount(t testing.TB, db *gorm.DB, table string, want int64, query ...any) {
+	t.Helper()
+	var got int64
+	tx := db.Table(table)
+	if len(query) > 0 {
+		tx = tx.Where(query[0], query[1:]...)
+	}
+	if err := tx.Count(&got).Error; err != nil {
+		t.Errorf("dbtest: count %s: %v", table, err)
+		return
+	}
+	if got != want {
+		t.Errorf("dbtest: table %s row count = %d, want %d", table, got, want)
+	}
+}
+
+// AssertRow 断言按 where 条件查到唯一一行，且该行的列值与 want 一致（只比较 want 中的列）
+func AssertRow(t testing.TB, db *gorm.DB, table string, where map[string]any, want map[string]any) {
+	t.Helper()
+	var rows []map[string]any
+	if err := db.Table(table).Where(where).Limit(2).Find(&rows).Error; err != nil {
+		t.Errorf("dbtest: query %s: %v", table, err)
+		return
+	}
+	if len(rows) != 1 {
+		t.Errorf("dbtest: table %s where %v matched %d rows, want 1", table, where, len(rows))
+		return
+	}
+	for column, wantValue := range want {
+		gotValue, ok := rows[0][column]
+		if !ok {
+			t.Errorf("dbtest: table %s has no column %s", table, column)
+			continue
+		}
+		if !equalValue(gotValue, wantValue) {
+			t.Errorf("dbtest: table %s where %v column %s = %v, want %v", table, where, column, gotValue, wantValue)
+		}
+	}
+}
+
+// AssertNoRow 断言按 where 条件查不到任何行
+func AssertNoRow(t testing.TB, db *gorm.DB, table string, where map[string]any) {
+	t.Helper()
+	var count int64
+	if err := db.Table(table).Where(where).Count(&count).Error; err != nil {
+		t.Errorf("dbtest: count %s: %v", table, err)
+		return
+	}
+	if count != 0 {
+		t.Errorf("dbtest: table %s where %v matched %d rows, want 0", table, where, count)
+	}
+}
+
+// equalValue 比较数据库返回值与期望值，数据库驱动返回的数值类型与 Go 字面量不一定一致，统一按字符串比较
+func equalValue(got, want any) bool {
+	if got == nil || want == nil {
+		return got == nil && want == nil
+	}
+	if b, ok := got.([]byte); ok {
+		got = string(b)
+	}
+	if b, ok := want.(bool); ok {
+		// SQLite 中布尔值存储为 0/1
+		if _, isBool := got.(bool); !isBool {
+			want = 0
+			if b {
+				want = 1
+			}
+		}
+	}
+	return fmt.Sprint(got) == fmt.Sprint(want)
+}
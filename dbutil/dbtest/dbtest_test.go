@@ -0,0 +1,94 @@
+package dbtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	ID     int64
+	Name   string
+	Active bool
+}
+
+type order struct {
+	ID     int64
+	UserID int64
+	User   *user
+	Amount int
+}
+
+func TestNew(t *testing.T) {
+	db := New(t, WithModels(&user{}, &order{}), WithFixtures("testdata/users.yaml", "testdata/orders.json"))
+	AssertCount(t, db, "users", 2)
+	AssertCount(t, db, "orders", 2, "user_id = ?", 2)
+	AssertRow(t, db, "users", map[string]any{"id": 1}, map[string]any{"name": "bob", "active": true})
+	AssertRow(t, db, "orders", map[string]any{"id": 3}, map[string]any{"amount": 70})
+	AssertNoRow(t, db, "users", map[string]any{"name": "carol"})
+
+	var o order
+	assert.NoError(t, db.Preload("User").First(&o, 2).Error)
+	assert.Equal(t, "alice", o.User.Name)
+}
+
+func TestBegin(t *testing.T) {
+	db := New(t, WithModels(&user{}, &order{}), WithFixtures("testdata/users.yaml"))
+	t.Run("create", func(t *testing.T) {
+		tx := Begin(t, db)
+		assert.NoError(t, tx.Create(&user{Name: "carol"}).Error)
+		AssertCount(t, tx, "users", 3)
+	})
+	t.Run("rolled back", func(t *testing.T) {
+		tx := Begin(t, db)
+		AssertCount(t, tx, "users", 2)
+		AssertNoRow(t, tx, "users", map[string]any{"name": "carol"})
+	})
+}
+
+func TestLoadFixtureData(t *testing.T) {
+	db := New(t, WithModels(&user{}))
+	assert.Error(t, LoadFixtureData(db, []byte(`- a`)))
+	assert.Error(t, LoadFixtureData(db, []byte(`missing: [{id: 1}]`)))
+	assert.NoError(t, LoadFixtureData(db, []byte(``)))
+	assert.Error(t, LoadFixtures(db, "testdata/users.csv"))
+}
+
+// fakeTB 记录断言失败信息，用于测试断言函数本身
+type fakeTB struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) FailNow() {
+	f.fatal = true
+}
+
+func (f *fakeTB) Failed() bool {
+	return len(f.errors) > 0 || f.fatal
+}
+
+func TestAssertions(t *testing.T) {
+	db := New(t, WithModels(&user{}, &order{}), WithFixtures("testdata/users.yaml"))
+	mock := &fakeTB{}
+	AssertCount(mock, db, "users", 3)
+	assert.True(t, mock.Failed())
+	assert.Len(t, mock.errors, 1)
+
+	mock = &fakeTB{}
+	AssertRow(mock, db, "users", map[string]any{"id": 1}, map[string]any{"name": "alice"})
+	assert.True(t, mock.Failed())
+
+	mock = &fakeTB{}
+	AssertNoRow(mock, db, "users", map[string]any{"id": 1})
+	assert.True(t, mock.Failed())
+	assert.Contains(t, mock.errors[0], "matched 1 rows, want 0")
+}
@@ -0,0 +1,192 @@
+package dbutil
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+const (
+	DialectMySQL    = "mysql"
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// Dialect 根据驱动名称返回 DSN 方言，未配置驱动时默认为 mysql
+func Dialect(driver string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(driver)) {
+	case "", "mysql":
+		return DialectMySQL, nil
+	case "postgres", "postgresql", "pgx":
+		return DialectPostgres, nil
+	case "sqlite", "sqlite3":
+		return DialectSQLite, nil
+	}
+	return "", fmt.Errorf("dbutil: unsupported driver %q", driver)
+}
+
+// BuildDSN 根据数据库配置构建 DSN。
+// 配置了 source 时直接使用（兼容旧配置），否则按驱动方言由 host、port、user、password、database、params、tls_mode 拼装，
+// 配置了 password_ref 时密码由 ResolvePassword 读取
+func BuildDSN(dbConfig *conf.Data_Database) (string, error) {
+	if dbConfig.GetSource() != "" {
+		return dbConfig.GetSource(), nil
+	}
+	dialect, err := Dialect(dbConfig.GetDriver())
+	if err != nil {
+		return "", err
+	}
+	password, err := ResolvePassword(dbConfig)
+	if err != nil {
+		return "", err
+	}
+	if password != dbConfig.GetPassword() {
+		dbConfig = proto.Clone(dbConfig).(*conf.Data_Database)
+		dbConfig.Password = password
+	}
+	switch dialect {
+	case DialectPostgres:
+		return buildPostgresDSN(dbConfig), nil
+	case DialectSQLite:
+		return buildSQLiteDSN(dbConfig)
+	default:
+		return buildMySQLDSN(dbConfig)
+	}
+}
+
+// ResolvePassword 返回数据库密码，配置了 password_ref 时按引用读取，否则返回 password。
+// 支持 env:NAME 读取环境变量和 file:PATH 读取文件（去掉末尾换行），读取到的密码会登记为敏感值，日志中被脱敏
+func ResolvePassword(dbConfig *conf.Data_Database) (string, error) {
+	ref := dbConfig.GetPasswordRef()
+	if ref == "" {
+		return dbConfig.GetPassword(), nil
+	}
+	scheme, name, _ := strings.Cut(ref, ":")
+	var password string
+	switch scheme {
+	case "env":
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("dbutil: password_ref %q: environment variable not set", ref)
+		}
+		password = value
+	case "file":
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("dbutil: password_ref %q: %w", ref, err)
+		}
+		password = strings.TrimRight(string(data), "\r\n")
+	default:
+		return "", fmt.Errorf("dbutil: password_ref %q: unsupported scheme, use env: or file:", ref)
+	}
+	conf.MarkSensitive(password)
+	return password, nil
+}
+
+// RedactDSN 将 DSN 中的密码替换为 ******，用于日志输出
+func RedactDSN(dsn string) string {
+	return conf.RedactDSN(dsn)
+}
+
+func buildMySQLDSN(dbConfig *conf.Data_Database) (string, error) {
+	if dbConfig.GetHost() == "" {
+		return "", fmt.Errorf("dbutil: database host is required")
+	}
+	port := dbConfig.GetPort()
+	if port == 0 {
+		port = 3306
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = dbConfig.GetUser()
+	cfg.Passwd = dbConfig.GetPassword()
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(dbConfig.GetHost(), strconv.Itoa(int(port)))
+	cfg.DBName = dbConfig.GetDatabase()
+	if len(dbConfig.GetParams()) > 0 {
+		cfg.Params = make(map[string]string, len(dbConfig.GetParams()))
+		for key, value := range dbConfig.GetParams() {
+			cfg.Params[key] = value
+		}
+	}
+	switch dbConfig.GetTlsMode() {
+	case conf.Data_Database_TLS_MODE_DISABLED:
+		cfg.TLSConfig = "false"
+	case conf.Data_Database_TLS_MODE_PREFERRED:
+		cfg.TLSConfig = "preferred"
+	case conf.Data_Database_TLS_MODE_REQUIRED:
+		cfg.TLSConfig = "skip-verify"
+	case conf.Data_Database_TLS_MODE_VERIFY_FULL:
+		cfg.TLSConfig = "true"
+	}
+	return cfg.FormatDSN(), nil
+}
+
+func buildPostgresDSN(dbConfig *conf.Data_Database) string {
+	pairs := map[string]string{
+		"host":     dbConfig.GetHost(),
+		"user":     dbConfig.GetUser(),
+		"password": dbConfig.GetPassword(),
+		"dbname":   dbConfig.GetDatabase(),
+	}
+	if dbConfig.GetPort() != 0 {
+		pairs["port"] = strconv.Itoa(int(dbConfig.GetPort()))
+	}
+	switch dbConfig.GetTlsMode() {
+	case conf.Data_Database_TLS_MODE_DISABLED:
+		pairs["sslmode"] = "disable"
+	case conf.Data_Database_TLS_MODE_PREFERRED:
+		pairs["sslmode"] = "prefer"
+	case conf.Data_Database_TLS_MODE_REQUIRED:
+		pairs["sslmode"] = "require"
+	case conf.Data_Database_TLS_MODE_VERIFY_FULL:
+		pairs["sslmode"] = "verify-full"
+	}
+	for key, value := range dbConfig.GetParams() {
+		pairs[key] = value
+	}
+	keys := make([]string, 0, len(pairs))
+	for key, value := range pairs {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+quotePostgresValue(pairs[key]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quotePostgresValue 值中包含空格、引号或反斜杠时使用单引号包裹并转义
+func quotePostgresValue(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+func buildSQLiteDSN(dbConfig *conf.Data_Database) (string, error) {
+	if dbConfig.GetDatabase() == "" {
+		return "", fmt.Errorf("dbutil: sqlite database file is required")
+	}
+	if len(dbConfig.GetParams()) == 0 {
+		return dbConfig.GetDatabase(), nil
+	}
+	query := url.Values{}
+	for key, value := range dbConfig.GetParams() {
+		query.Set(key, value)
+	}
+	return dbConfig.GetDatabase() + "?" + query.Encode(), nil
+}
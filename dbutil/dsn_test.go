@@ -0,0 +1,124 @@
+package dbutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+func TestBuildDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbConfig *conf.Data_Database
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "source has priority",
+			dbConfig: &conf.Data_Database{Source: "root:root@tcp(127.0.0.1:3306)/test", Host: "db"},
+			want:     "root:root@tcp(127.0.0.1:3306)/test",
+		},
+		{
+			name: "mysql",
+			dbConfig: &conf.Data_Database{
+				Driver:   "mysql",
+				Host:     "127.0.0.1",
+				User:     "root",
+				Password: "p@ss:word",
+				Database: "test",
+				Params:   map[string]string{"charset": "utf8mb4", "parseTime": "True"},
+				TlsMode:  conf.Data_Database_TLS_MODE_VERIFY_FULL,
+			},
+			want: "root:p@ss:word@tcp(127.0.0.1:3306)/test?tls=true&charset=utf8mb4&parseTime=True",
+		},
+		{
+			name:     "mysql without host",
+			dbConfig: &conf.Data_Database{Database: "test"},
+			wantErr:  true,
+		},
+		{
+			name: "postgres",
+			dbConfig: &conf.Data_Database{
+				Driver:   "postgres",
+				Host:     "localhost",
+				Port:     5432,
+				User:     "app",
+				Password: "it's secret",
+				Database: "test",
+				TlsMode:  conf.Data_Database_TLS_MODE_DISABLED,
+			},
+			want: `dbname=test host=localhost password='it\'s secret' port=5432 sslmode=disable user=app`,
+		},
+		{
+			name:     "sqlite",
+			dbConfig: &conf.Data_Database{Driver: "sqlite3", Database: "test.db", Params: map[string]string{"_fk": "1"}},
+			want:     "test.db?_fk=1",
+		},
+		{
+			name:     "unsupported driver",
+			dbConfig: &conf.Data_Database{Driver: "oracle", Host: "db"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildDSN(tt.dbConfig)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.dbConfig.Driver == "mysql" {
+				// 参数顺序不固定，解析后比较
+				gotConfig, err := mysql.ParseDSN(got)
+				assert.NoError(t, err)
+				wantConfig, _ := mysql.ParseDSN(tt.want)
+				assert.Equal(t, wantConfig, gotConfig)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRedactDSN(t *testing.T) {
+	assert.Equal(t, "root:******@tcp(127.0.0.1:3306)/test", RedactDSN("root:p@ss@tcp(127.0.0.1:3306)/test"))
+	assert.Equal(t, "host=db password=****** user=app", RedactDSN("host=db password='a b' user=app"))
+	assert.Equal(t, "postgres://app:******@db:5432/test", RedactDSN("postgres://app:secret@db:5432/test"))
+	assert.Equal(t, "test.db", RedactDSN("test.db"))
+}
+
+func TestResolvePassword(t *testing.T) {
+	t.Setenv("CLAYMORE_TEST_DB_PASSWORD", "env-pass")
+	file := filepath.Join(t.TempDir(), "password")
+	assert.NoError(t, os.WriteFile(file, []byte("file-pass\n"), 0o600))
+
+	password, err := ResolvePassword(&conf.Data_Database{Password: "plain"})
+	assert.NoError(t, err)
+	assert.Equal(t, "plain", password)
+
+	password, err = ResolvePassword(&conf.Data_Database{Password: "plain", PasswordRef: "env:CLAYMORE_TEST_DB_PASSWORD"})
+	assert.NoError(t, err)
+	assert.Equal(t, "env-pass", password)
+
+	password, err = ResolvePassword(&conf.Data_Database{PasswordRef: "file:" + file})
+	assert.NoError(t, err)
+	assert.Equal(t, "file-pass", password)
+	assert.True(t, conf.IsSensitive("file-pass"))
+
+	for _, ref := range []string{"env:CLAYMORE_TEST_MISSING", "file:" + file + ".missing", "vault:db"} {
+		_, err = ResolvePassword(&conf.Data_Database{PasswordRef: ref})
+		assert.Error(t, err, ref)
+	}
+
+	dbConfig := &conf.Data_Database{Driver: "mysql", Host: "db", User: "root", PasswordRef: "env:CLAYMORE_TEST_DB_PASSWORD"}
+	dsn, err := BuildDSN(dbConfig)
+	assert.NoError(t, err)
+	assert.Contains(t, dsn, "root:env-pass@tcp(db:3306)")
+	assert.Empty(t, dbConfig.GetPassword())
+}
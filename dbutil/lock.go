@@ -0,0 +1,369 @@
+package dbutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrLockNotAcquired 锁已被其他实例持有
+	ErrLockNotAcquired = errors.New("dbutil: lock not acquired")
+	// ErrLockLost 锁已过期并被其他实例抢占，或已释放
+	ErrLockLost = errors.New("dbutil: lock lost")
+)
+
+// LockLease 租约锁表，每个锁一行，释放锁时不删除行，以保证 fencing token 单调递增
+type LockLease struct {
+	Name      string    `gorm:"primaryKey;size:128"`
+	Owner     string    `gorm:"size:128"`
+	Token     int64     `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"index"`
+	UpdatedAt time.Time
+}
+
+// TableName 租约锁默认表名
+func (LockLease) TableName() string {
+	return "lock_leases"
+}
+
+// LockOption 分布式锁配置
+type LockOption func(m *LockManager)
+
+// WithLockOwner 锁持有者标识，默认为 主机名-进程号-随机串
+func WithLockOwner(owner string) LockOption {
+	return func(m *LockManager) {
+		m.owner = owner
+	}
+}
+
+// WithLockTTL 租约时长，持有期间每 ttl/3 自动续约一次，默认 15 秒，小于等于 0 时使用默认值
+func WithLockTTL(ttl time.Duration) LockOption {
+	return func(m *LockManager) {
+		m.ttl = ttl
+	}
+}
+
+// WithLockRetryInterval Lock 阻塞等待时的重试间隔，默认 1 秒，小于等于 0 时使用默认值
+func WithLockRetryInterval(interval time.Duration) LockOption {
+	return func(m *LockManager) {
+		m.retryInterval = interval
+	}
+}
+
+// LockManager 基于数据库租约表的分布式锁。
+// 过期判断使用各实例本地时间，部署时需保证实例间时钟同步，并让 ttl 远大于时钟偏差。
+type LockManager struct {
+	db            *gorm.DB
+	owner         string
+	ttl           time.Duration
+	retryInterval time.Duration
+}
+
+const (
+	defaultLockTTL           = 15 * time.Second
+	defaultLockRetryInterval = time.Second
+	// minLockRenewInterval 续约间隔下限，ttl 极小时 ttl/3 可能为 0
+	minLockRenewInterval = time.Millisecond
+)
+
+// NewLockManager 创建分布式锁管理器，需事先迁移 LockLease 表
+func NewLockManager(db *gorm.DB, opts ...LockOption) *LockManager {
+	m := &LockManager{
+		db:            db,
+		owner:         defaultLockOwner(),
+		ttl:           defaultLockTTL,
+		retryInterval: defaultLockRetryInterval,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	// ttl 为 0 时后台续约的 time.NewTicker 会 panic，重试间隔为 0 时 Lock 会持续空转
+	if m.ttl <= 0 {
+		m.ttl = defaultLockTTL
+	}
+	if m.retryInterval <= 0 {
+		m.retryInterval = defaultLockRetryInterval
+	}
+	return m
+}
+
+// Owner 当前实例的锁持有者标识
+func (m *LockManager) Owner() string {
+	return m.owner
+}
+
+// TryLock 尝试获取锁，锁被其他实例持有时立即返回 ErrLockNotAcquired
+func (m *LockManager) TryLock(ctx context.Context, name string) (*Lock, error) {
+	token, err := m.acquire(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return m.newLock(ctx, name, token), nil
+}
+
+// Lock 获取锁，锁被占用时按重试间隔等待，直到获取成功或 ctx 取消
+func (m *LockManager) Lock(ctx context.Context, name string) (*Lock, error) {
+	for {
+		lock, err := m.TryLock(ctx, name)
+		if !errors.Is(err, ErrLockNotAcquired) {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(m.retryInterval):
+		}
+	}
+}
+
+// acquire 新建锁记录或抢占已过期的锁，返回新的 fencing token
+func (m *LockManager) acquire(ctx context.Context, name string) (int64, error) {
+	var token int64
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		lease := &LockLease{Name: name, Owner: m.owner, Token: 1, ExpiresAt: now.Add(m.ttl)}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(lease)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			token = lease.Token
+			return nil
+		}
+		result = tx.Model(&LockLease{}).
+			Where("name = ? AND expires_at <= ?", name, now).
+			Updates(map[string]any{
+				"owner":      m.owner,
+				"token":      gorm.Expr("token + 1"),
+				"expires_at": now.Add(m.ttl),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrLockNotAcquired
+		}
+		return tx.Model(&LockLease{}).Where("name = ?", name).Pluck("token", &token).Error
+	})
+	return token, err
+}
+
+func (m *LockManager) newLock(ctx context.Context, name string, token int64) *Lock {
+	lockCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	l := &Lock{
+		manager: m,
+		name:    name,
+		token:   token,
+		ctx:     lockCtx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go l.keepAlive()
+	return l
+}
+
+// Lock 已获取的分布式锁，持有期间后台自动续约
+type Lock struct {
+	manager *LockManager
+	name    string
+	token   int64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Name 锁名称
+func (l *Lock) Name() string {
+	return l.name
+}
+
+// Token fencing token，每次获取锁单调递增。
+// 写入下游存储时携带该值，下游拒绝比已见过的 token 更小的请求，即可防止旧持有者在失去锁后继续写入。
+func (l *Lock) Token() int64 {
+	return l.token
+}
+
+// Context 锁失效（续约失败或释放）时取消的 context，持锁执行的任务应监听它
+func (l *Lock) Context() context.Context {
+	return l.ctx
+}
+
+// Refresh 手动续约，锁已被抢占时返回 ErrLockLost
+func (l *Lock) Refresh(ctx context.Context) error {
+	if l.ctx.Err() != nil {
+		return ErrLockLost
+	}
+	held, err := l.setExpiresAt(ctx, time.Now().Add(l.manager.ttl))
+	if err != nil {
+		return err
+	}
+	if !held {
+		l.release()
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Unlock 释放锁，锁已失效时返回 ErrLockLost
+func (l *Lock) Unlock(ctx context.Context) error {
+	if l.ctx.Err() != nil {
+		return ErrLockLost
+	}
+	l.release()
+	held, err := l.setExpiresAt(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if !held {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// setExpiresAt 更新租约过期时间，返回锁是否仍由当前实例持有。
+// MySQL 返回的是实际变更的行数，时间精度为秒时同一秒内的两次续约不会变更任何行，
+// 因此影响行数为 0 时重新查询租约确认
+func (l *Lock) setExpiresAt(ctx context.Context, expiresAt time.Time) (bool, error) {
+	result := l.manager.db.WithContext(ctx).Model(&LockLease{}).
+		Where("name = ? AND owner = ? AND token = ?", l.name, l.manager.owner, l.token).
+		Update("expires_at", expiresAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+	var count int64
+	if err := l.manager.db.WithContext(ctx).Model(&LockLease{}).
+		Where("name = ? AND owner = ? AND token = ?", l.name, l.manager.owner, l.token).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// release 停止续约并取消锁的 context
+func (l *Lock) release() {
+	l.once.Do(func() {
+		l.cancel()
+		<-l.done
+	})
+}
+
+// keepAlive 每 ttl/3 续约一次，续约失败（包括数据库不可用直到租约过期）时释放锁
+func (l *Lock) keepAlive() {
+	defer close(l.done)
+	ticker := time.NewTicker(max(l.manager.ttl/3, minLockRenewInterval))
+	defer ticker.Stop()
+	expiresAt := time.Now().Add(l.manager.ttl)
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		held, err := l.setExpiresAt(l.ctx, time.Now().Add(l.manager.ttl))
+		switch {
+		case err == nil && held:
+			expiresAt = time.Now().Add(l.manager.ttl)
+		case err == nil, time.Now().After(expiresAt):
+			// 锁已被抢占，或一直续约失败直到租约过期
+			l.cancel()
+			return
+		}
+	}
+}
+
+// defaultLockOwner 主机名-进程号-随机串
+func defaultLockOwner() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(b))
+}
+
+// LeaderElector 基于分布式锁的选主，同一时刻只有一个实例成为 leader
+type LeaderElector struct {
+	manager *LockManager
+	name    string
+
+	mu     sync.RWMutex
+	leader *Lock
+}
+
+// NewLeaderElector 创建选主器，name 为选主使用的锁名称
+func NewLeaderElector(manager *LockManager, name string) *LeaderElector {
+	return &LeaderElector{manager: manager, name: name}
+}
+
+// IsLeader 当前实例是否为 leader
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader != nil && e.leader.Context().Err() == nil
+}
+
+// Token 当前任期的 fencing token，不是 leader 时返回 0
+func (e *LeaderElector) Token() int64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.leader == nil {
+		return 0
+	}
+	return e.leader.Token()
+}
+
+// Run 参与选主，成为 leader 后执行 fn，fn 的 ctx 在失去 leader 身份或 ctx 取消时取消。
+// fn 返回后释放 leader 身份并重新参与选主，直到 ctx 取消。
+func (e *LeaderElector) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	for {
+		lock, err := e.manager.Lock(ctx, e.name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// 数据库暂时不可用，稍后重试
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(e.manager.retryInterval):
+			}
+			continue
+		}
+		e.setLeader(lock)
+		err = e.lead(ctx, lock, fn)
+		e.setLeader(nil)
+		_ = lock.Unlock(context.WithoutCancel(ctx))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			e.manager.db.Logger.Error(ctx, "dbutil: leader %s task error: %v", e.name, err)
+		}
+	}
+}
+
+func (e *LeaderElector) lead(ctx context.Context, lock *Lock, fn func(ctx context.Context) error) error {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(lock.Context(), cancel)
+	defer stop()
+	return fn(leaderCtx)
+}
+
+func (e *LeaderElector) setLeader(lock *Lock) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leader = lock
+}
@@ -0,0 +1,157 @@
+package dbutil
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/lastares/claymore/dbutil/dbtest"
+)
+
+func TestLockManager_TryLock(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&LockLease{}))
+	ctx := context.Background()
+	a := NewLockManager(db, WithLockOwner("a"))
+	b := NewLockManager(db, WithLockOwner("b"))
+
+	lockA, err := a.TryLock(ctx, "job")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), lockA.Token())
+
+	_, err = b.TryLock(ctx, "job")
+	assert.ErrorIs(t, err, ErrLockNotAcquired)
+
+	assert.NoError(t, lockA.Unlock(ctx))
+	assert.Error(t, lockA.Context().Err())
+	assert.ErrorIs(t, lockA.Unlock(ctx), ErrLockLost)
+
+	lockB, err := b.TryLock(ctx, "job")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), lockB.Token())
+	assert.NoError(t, lockB.Refresh(ctx))
+	assert.NoError(t, lockB.Unlock(ctx))
+}
+
+func TestLockManager_Lost(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&LockLease{}))
+	ctx := context.Background()
+	m := NewLockManager(db, WithLockOwner("a"), WithLockTTL(30*time.Millisecond))
+	lock, err := m.TryLock(ctx, "job")
+	assert.NoError(t, err)
+
+	// 模拟租约被其他实例抢占
+	assert.NoError(t, db.Model(&LockLease{}).Where("name = ?", "job").
+		Updates(map[string]any{"owner": "b", "token": 2}).Error)
+	select {
+	case <-lock.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("lock context should be canceled after lease is lost")
+	}
+	assert.ErrorIs(t, lock.Refresh(ctx), ErrLockLost)
+}
+
+func TestLockManager_UnchangedRenewal(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&LockLease{}))
+	// 模拟 MySQL 返回实际变更的行数：同一秒内续约时影响行数为 0
+	assert.NoError(t, db.Callback().Update().After("gorm:update").Register("test:changed_rows", func(tx *gorm.DB) {
+		if tx.Statement.Table == "lock_leases" {
+			tx.RowsAffected = 0
+		}
+	}))
+	ctx := context.Background()
+	m := NewLockManager(db, WithLockOwner("a"), WithLockTTL(30*time.Millisecond))
+	lock, err := m.TryLock(ctx, "job")
+	assert.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, lock.Refresh(ctx))
+	assert.NoError(t, lock.Context().Err())
+
+	assert.NoError(t, db.Model(&LockLease{}).Where("name = ?", "job").
+		Updates(map[string]any{"owner": "b", "token": 2}).Error)
+	assert.ErrorIs(t, lock.Refresh(ctx), ErrLockLost)
+}
+
+func TestNewLockManager_Defaults(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&LockLease{}))
+	ctx := context.Background()
+	for _, ttl := range []time.Duration{0, -time.Second, time.Nanosecond} {
+		m := NewLockManager(db, WithLockTTL(ttl), WithLockRetryInterval(0))
+		assert.Equal(t, defaultLockRetryInterval, m.retryInterval)
+		if ttl <= 0 {
+			assert.Equal(t, defaultLockTTL, m.ttl)
+		}
+		lock, err := m.TryLock(ctx, "defaults")
+		assert.NoError(t, err)
+		assert.NoError(t, lock.Unlock(ctx))
+	}
+}
+
+func TestLockManager_KeepAlive(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&LockLease{}))
+	ctx := context.Background()
+	a := NewLockManager(db, WithLockOwner("a"), WithLockTTL(30*time.Millisecond))
+	lock, err := a.TryLock(ctx, "job")
+	assert.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	_, err = NewLockManager(db, WithLockOwner("b")).TryLock(ctx, "job")
+	assert.ErrorIs(t, err, ErrLockNotAcquired)
+	assert.NoError(t, lock.Context().Err())
+	assert.NoError(t, lock.Unlock(ctx))
+}
+
+func TestLockManager_LockTimeout(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&LockLease{}))
+	a := NewLockManager(db, WithLockOwner("a"))
+	lock, err := a.Lock(context.Background(), "job")
+	assert.NoError(t, err)
+	defer lock.Unlock(context.Background())
+
+	b := NewLockManager(db, WithLockOwner("b"), WithLockRetryInterval(10*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = b.Lock(ctx, "job")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestLeaderElector(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&LockLease{}))
+	ctx, cancel := context.WithCancel(context.Background())
+	var (
+		leaders int32
+		runs    int32
+		wg      sync.WaitGroup
+	)
+	electors := make([]*LeaderElector, 3)
+	for i := range electors {
+		m := NewLockManager(db, WithLockTTL(60*time.Millisecond), WithLockRetryInterval(5*time.Millisecond))
+		electors[i] = NewLeaderElector(m, "scheduler")
+		wg.Add(1)
+		go func(e *LeaderElector) {
+			defer wg.Done()
+			_ = e.Run(ctx, func(ctx context.Context) error {
+				if atomic.AddInt32(&leaders, 1) > 1 {
+					t.Error("more than one leader")
+				}
+				atomic.AddInt32(&runs, 1)
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&leaders, -1)
+				return nil
+			})
+		}(electors[i])
+	}
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) >= 3
+	}, 2*time.Second, 5*time.Millisecond)
+	cancel()
+	wg.Wait()
+	for _, e := range electors {
+		assert.False(t, e.IsLeader())
+	}
+}
@@ -0,0 +1,137 @@
+package dbutil
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// versionField 乐观锁版本号字段名
+const versionField = "Version"
+
+// Versioned 乐观锁版本号，嵌入到模型中即可启用乐观锁，例如:
+//
+//	type User struct {
+//		ID   int64
+//		Name string
+//		dbutil.Versioned
+//	}
+type Versioned struct {
+	Version int64 `gorm:"not null;default:1"`
+}
+
+// GetVersion 返回当前版本号
+func (v *Versioned) GetVersion() int64 {
+	return v.Version
+}
+
+// SetVersion 设置版本号
+func (v *Versioned) SetVersion(version int64) {
+	v.Version = version
+}
+
+// VersionedModel 支持乐观锁的模型，嵌入 Versioned 的模型指针自动实现该接口
+type VersionedModel interface {
+	GetVersion() int64
+	SetVersion(version int64)
+}
+
+// ErrStaleObject 乐观锁冲突：记录已被其他事务修改（版本号不匹配）或已被删除
+type ErrStaleObject struct {
+	Table   string
+	Version int64
+}
+
+func (e *ErrStaleObject) Error() string {
+	return fmt.Sprintf("dbutil: stale object, table=%s version=%d", e.Table, e.Version)
+}
+
+// IsStaleObject 判断错误是否为乐观锁冲突
+func IsStaleObject(err error) bool {
+	var stale *ErrStaleObject
+	return errors.As(err, &stale)
+}
+
+// UpdateWithVersion 带版本校验的部分更新，仅当数据库中的版本号与 model 一致时才更新，并将版本号加一。
+// 参数:
+//
+//	db - gorm 实例，可以是事务 tx，也可以携带 WithContext 等链式条件
+//	model - 待更新的模型指针，主键不能为空
+//	values - 需要更新的列，key 为数据库列名
+//
+// 返回值: 版本冲突时返回 *ErrStaleObject，更新成功后 model 的版本号同步加一。
+func UpdateWithVersion(db *gorm.DB, model VersionedModel, values map[string]any) error {
+	column, err := versionColumn(db, model)
+	if err != nil {
+		return err
+	}
+	current := model.GetVersion()
+	updates := make(map[string]any, len(values)+1)
+	maps.Copy(updates, values)
+	updates[column] = current + 1
+
+	result := db.Model(model).Where(versionEq(column, current)).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &ErrStaleObject{Table: result.Statement.Table, Version: current}
+	}
+	model.SetVersion(current + 1)
+	return nil
+}
+
+// SaveWithVersion 带版本校验的整行更新，所有字段（包括零值）都会写入数据库。
+// 版本冲突时返回 *ErrStaleObject，且 model 的版本号保持不变。
+func SaveWithVersion(db *gorm.DB, model VersionedModel) error {
+	column, err := versionColumn(db, model)
+	if err != nil {
+		return err
+	}
+	current := model.GetVersion()
+	model.SetVersion(current + 1)
+
+	result := db.Model(model).Where(versionEq(column, current)).Select("*").Updates(model)
+	if result.Error != nil {
+		model.SetVersion(current)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		model.SetVersion(current)
+		return &ErrStaleObject{Table: result.Statement.Table, Version: current}
+	}
+	return nil
+}
+
+// versionColumn 解析模型的版本号列名，同时确保主键不为空，避免按版本号批量更新
+func versionColumn(db *gorm.DB, model any) (string, error) {
+	sch, err := parseSchema(db, model)
+	if err != nil {
+		return "", err
+	}
+	field := sch.LookUpField(versionField)
+	if field == nil {
+		return "", fmt.Errorf("dbutil: model %s has no %s field", sch.Name, versionField)
+	}
+	if !hasPrimaryKey(db, model) {
+		return "", gorm.ErrPrimaryKeyRequired
+	}
+	return field.DBName, nil
+}
+
+func versionEq(column string, version int64) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: version}
+}
+
+// parseSchema 解析模型对应的 gorm schema
+func parseSchema(db *gorm.DB, model any) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
@@ -0,0 +1,93 @@
+package dbutil
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/lastares/claymore/dbutil/dbtest"
+)
+
+type account struct {
+	ID      int64
+	Name    string
+	Balance int
+	Versioned
+}
+
+func TestUpdateWithVersion(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&account{}))
+	a := &account{Name: "a", Balance: 100}
+	assert.NoError(t, db.Create(a).Error)
+	assert.Equal(t, int64(1), a.Version)
+
+	stale := &account{}
+	assert.NoError(t, db.First(stale, a.ID).Error)
+
+	assert.NoError(t, UpdateWithVersion(db, a, map[string]any{"balance": 80}))
+	assert.Equal(t, int64(2), a.Version)
+
+	err := UpdateWithVersion(db, stale, map[string]any{"balance": 50})
+	assert.True(t, IsStaleObject(err))
+	var staleErr *ErrStaleObject
+	assert.True(t, errors.As(err, &staleErr))
+	assert.Equal(t, int64(1), staleErr.Version)
+	assert.Equal(t, "accounts", staleErr.Table)
+
+	got := &account{}
+	assert.NoError(t, db.First(got, a.ID).Error)
+	assert.Equal(t, 80, got.Balance)
+	assert.Equal(t, int64(2), got.Version)
+}
+
+func TestUpdateWithVersion_Transaction(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&account{}))
+	a := &account{Name: "a", Balance: 100}
+	assert.NoError(t, db.Create(a).Error)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := UpdateWithVersion(tx, a, map[string]any{"balance": 10}); err != nil {
+			return err
+		}
+		stale := &account{ID: a.ID, Versioned: Versioned{Version: 1}}
+		return UpdateWithVersion(tx, stale, map[string]any{"balance": 20})
+	})
+	assert.True(t, IsStaleObject(err))
+
+	got := &account{}
+	assert.NoError(t, db.First(got, a.ID).Error)
+	assert.Equal(t, 100, got.Balance)
+	assert.Equal(t, int64(1), got.Version)
+}
+
+func TestUpdateWithVersion_PrimaryKeyRequired(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&account{}))
+	err := UpdateWithVersion(db, &account{}, map[string]any{"balance": 10})
+	assert.ErrorIs(t, err, gorm.ErrPrimaryKeyRequired)
+}
+
+func TestSaveWithVersion(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&account{}))
+	a := &account{Name: "a", Balance: 100}
+	assert.NoError(t, db.Create(a).Error)
+	stale := &account{}
+	assert.NoError(t, db.First(stale, a.ID).Error)
+
+	a.Name = "b"
+	a.Balance = 0
+	assert.NoError(t, SaveWithVersion(db, a))
+	assert.Equal(t, int64(2), a.Version)
+
+	stale.Name = "c"
+	err := SaveWithVersion(db, stale)
+	assert.True(t, IsStaleObject(err))
+	assert.Equal(t, int64(1), stale.Version)
+
+	got := &account{}
+	assert.NoError(t, db.First(got, a.ID).Error)
+	assert.Equal(t, "b", got.Name)
+	assert.Equal(t, 0, got.Balance)
+	assert.Equal(t, int64(2), got.Version)
+}
@@ -0,0 +1,331 @@
+package dbutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed" // 超过最大重试次数，不再投递
+
+	// outboxCleanupInterval Run 清理已发送事件的间隔
+	outboxCleanupInterval = time.Hour
+)
+
+// OutboxEvent 发件箱中的事件，与业务数据在同一事务中写入
+type OutboxEvent struct {
+	ID            uint64 `gorm:"primaryKey"`
+	Topic         string `gorm:"size:128"`
+	Key           string `gorm:"size:128"`
+	Payload       []byte
+	Headers       string     `gorm:"type:text"` // map[string]string 的 JSON
+	Status        string     `gorm:"size:16;index:idx_outbox_status_next"`
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `gorm:"index:idx_outbox_status_next"`
+	LastError     string     `gorm:"type:text"`
+	CreatedAt     time.Time  `gorm:"index"`
+	SentAt        *time.Time `gorm:"index"`
+}
+
+// TableName 发件箱默认表名
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// HeaderMap 解析事件头
+func (e *OutboxEvent) HeaderMap() (map[string]string, error) {
+	headers := make(map[string]string)
+	if e.Headers == "" {
+		return headers, nil
+	}
+	err := json.Unmarshal([]byte(e.Headers), &headers)
+	return headers, err
+}
+
+// Event 待发布的领域事件
+type Event struct {
+	Topic   string
+	Key     string
+	Payload []byte
+	Headers map[string]string
+}
+
+// EnqueueOutbox 将事件写入发件箱，需传入业务事务 tx，保证事件与业务数据同时提交或回滚:
+//
+//	db.Transaction(func(tx *gorm.DB) error {
+//		if err := tx.Create(order).Error; err != nil {
+//			return err
+//		}
+//		return dbutil.EnqueueOutbox(tx, &dbutil.Event{Topic: "order.created", Payload: payload})
+//	})
+func EnqueueOutbox(tx *gorm.DB, events ...*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	now := time.Now()
+	records := make([]*OutboxEvent, 0, len(events))
+	for _, event := range events {
+		record := &OutboxEvent{
+			Topic:         event.Topic,
+			Key:           event.Key,
+			Payload:       event.Payload,
+			Status:        OutboxStatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		if len(event.Headers) > 0 {
+			headers, err := json.Marshal(event.Headers)
+			if err != nil {
+				return err
+			}
+			record.Headers = string(headers)
+		}
+		records = append(records, record)
+	}
+	return tx.Create(records).Error
+}
+
+// Publisher 事件发布器，由使用方对接具体的消息队列
+type Publisher interface {
+	Publish(ctx context.Context, event *OutboxEvent) error
+}
+
+// PublisherFunc 函数形式的 Publisher
+type PublisherFunc func(ctx context.Context, event *OutboxEvent) error
+
+func (f PublisherFunc) Publish(ctx context.Context, event *OutboxEvent) error {
+	return f(ctx, event)
+}
+
+// MemoryPublisher 内存发布器，将事件保存在内存中，用于测试
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []*OutboxEvent
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, event *OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+// Events 返回已发布的事件
+func (p *MemoryPublisher) Events() []*OutboxEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*OutboxEvent(nil), p.events...)
+}
+
+// OutboxOption 发件箱投递器配置
+type OutboxOption func(r *OutboxRelay)
+
+// WithOutboxBatchSize 每次轮询处理的最大事件数，默认 100，小于 1 时使用默认值
+func WithOutboxBatchSize(size int) OutboxOption {
+	return func(r *OutboxRelay) {
+		r.batchSize = size
+	}
+}
+
+// WithOutboxPollInterval 轮询间隔，默认 1 秒，小于等于 0 时使用默认值
+func WithOutboxPollInterval(interval time.Duration) OutboxOption {
+	return func(r *OutboxRelay) {
+		r.pollInterval = interval
+	}
+}
+
+// WithOutboxMaxAttempts 最大投递次数，超过后事件标记为 failed，默认 10
+func WithOutboxMaxAttempts(attempts int) OutboxOption {
+	return func(r *OutboxRelay) {
+		r.maxAttempts = attempts
+	}
+}
+
+// WithOutboxBackoff 重试退避的初始间隔与上限，每次失败间隔翻倍，默认 1 秒 ~ 5 分钟
+func WithOutboxBackoff(base, maxBackoff time.Duration) OutboxOption {
+	return func(r *OutboxRelay) {
+		r.backoff = base
+		r.maxBackoff = maxBackoff
+	}
+}
+
+// WithOutboxRetention 已发送事件的保留时长，Run 会定期清理过期事件，默认 7 天，0 表示不清理
+func WithOutboxRetention(retention time.Duration) OutboxOption {
+	return func(r *OutboxRelay) {
+		r.retention = retention
+	}
+}
+
+// OutboxRelay 发件箱投递器，轮询发件箱并将事件投递到 Publisher。
+// MySQL/PostgreSQL 下使用 SELECT ... FOR UPDATE SKIP LOCKED，多个实例可以同时运行。
+type OutboxRelay struct {
+	db        *gorm.DB
+	publisher Publisher
+
+	batchSize    int
+	pollInterval time.Duration
+	maxAttempts  int
+	backoff      time.Duration
+	maxBackoff   time.Duration
+	retention    time.Duration
+}
+
+const (
+	defaultOutboxBatchSize    = 100
+	defaultOutboxPollInterval = time.Second
+)
+
+// NewOutboxRelay 创建发件箱投递器
+func NewOutboxRelay(db *gorm.DB, publisher Publisher, opts ...OutboxOption) *OutboxRelay {
+	r := &OutboxRelay{
+		db:           db,
+		publisher:    publisher,
+		batchSize:    defaultOutboxBatchSize,
+		pollInterval: defaultOutboxPollInterval,
+		maxAttempts:  10,
+		backoff:      time.Second,
+		maxBackoff:   5 * time.Minute,
+		retention:    7 * 24 * time.Hour,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	// 批量为 0 时 Run 会认为每批都已处理满而持续空转，轮询间隔为 0 时 time.NewTicker 会 panic
+	if r.batchSize < 1 {
+		r.batchSize = defaultOutboxBatchSize
+	}
+	if r.pollInterval <= 0 {
+		r.pollInterval = defaultOutboxPollInterval
+	}
+	return r
+}
+
+// Run 持续轮询投递，直到 ctx 取消
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	var lastCleanup time.Time
+	for {
+		// 一批处理满时立即处理下一批，否则等待下次轮询
+		n, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.db.Logger.Error(ctx, "dbutil: outbox relay error: %v", err)
+		}
+		if r.retention > 0 && time.Since(lastCleanup) >= outboxCleanupInterval {
+			if _, err = r.Cleanup(ctx); err != nil && ctx.Err() == nil {
+				r.db.Logger.Error(ctx, "dbutil: outbox cleanup error: %v", err)
+			}
+			lastCleanup = time.Now()
+		}
+		if n == r.batchSize && err == nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce 处理一批到期的待投递事件，返回处理的事件数（包括投递失败的事件）。
+// ctx 在处理过程中取消时停止投递剩余事件，已交给 Publisher 的事件仍会提交投递状态，避免重复投递，
+// 此时返回已处理的数量和 ctx.Err()
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	var processed int
+	// 事务不随 ctx 取消，保证已投递事件的状态能够提交
+	err := r.db.WithContext(context.WithoutCancel(ctx)).Transaction(func(tx *gorm.DB) error {
+		var events []*OutboxEvent
+		query := tx.Where("status = ? AND next_attempt_at <= ?", OutboxStatusPending, time.Now()).
+			Order("id").Limit(r.batchSize)
+		if supportsSkipLocked(tx) {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		if err := query.Find(&events).Error; err != nil {
+			return err
+		}
+		for _, event := range events {
+			if ctx.Err() != nil {
+				break
+			}
+			if err := tx.Model(event).Updates(r.deliver(ctx, event)).Error; err != nil {
+				return err
+			}
+			processed++
+		}
+		return nil
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	return processed, err
+}
+
+// deliver 投递单个事件，返回需要更新的字段
+func (r *OutboxRelay) deliver(ctx context.Context, event *OutboxEvent) map[string]any {
+	attempts := event.Attempts + 1
+	err := r.publish(ctx, event)
+	if err == nil {
+		return map[string]any{"status": OutboxStatusSent, "attempts": attempts, "sent_at": time.Now(), "last_error": ""}
+	}
+	updates := map[string]any{"attempts": attempts, "last_error": err.Error()}
+	if attempts >= r.maxAttempts {
+		updates["status"] = OutboxStatusFailed
+		return updates
+	}
+	updates["next_attempt_at"] = time.Now().Add(r.retryDelay(attempts))
+	return updates
+}
+
+// publish 调用 Publisher，并将 panic 转换为错误，避免单个事件阻塞整个发件箱
+func (r *OutboxRelay) publish(ctx context.Context, event *OutboxEvent) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("dbutil: outbox publisher panic: %v", p)
+		}
+	}()
+	return r.publisher.Publish(ctx, event)
+}
+
+// retryDelay 第 attempts 次失败后的重试间隔
+func (r *OutboxRelay) retryDelay(attempts int) time.Duration {
+	delay := r.backoff
+	for i := 1; i < attempts && delay < r.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.maxBackoff)
+}
+
+// Cleanup 删除保留期之前已发送的事件，返回删除数量
+func (r *OutboxRelay) Cleanup(ctx context.Context) (int64, error) {
+	if r.retention <= 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND sent_at < ?", OutboxStatusSent, time.Now().Add(-r.retention)).
+		Delete(&OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// supportsSkipLocked 判断数据库是否支持 FOR UPDATE SKIP LOCKED
+func supportsSkipLocked(db *gorm.DB) bool {
+	switch db.Dialector.Name() {
+	case "mysql", "postgres":
+		return true
+	}
+	return false
+}
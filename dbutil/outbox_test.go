@@ -0,0 +1,177 @@
+package dbutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/lastares/claymore/dbutil/dbtest"
+)
+
+type order struct {
+	ID     int64
+	Amount int
+}
+
+func TestEnqueueOutbox(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&order{}, &OutboxEvent{}))
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&order{Amount: 1}).Error; err != nil {
+			return err
+		}
+		if err := EnqueueOutbox(tx, &Event{Topic: "order.created", Key: "1"}); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	assert.Error(t, err)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&order{Amount: 2}).Error; err != nil {
+			return err
+		}
+		return EnqueueOutbox(tx, &Event{
+			Topic:   "order.created",
+			Key:     "2",
+			Payload: []byte(`{"amount":2}`),
+			Headers: map[string]string{"trace_id": "abc"},
+		})
+	})
+	assert.NoError(t, err)
+
+	var events []*OutboxEvent
+	assert.NoError(t, db.Find(&events).Error)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "2", events[0].Key)
+	assert.Equal(t, OutboxStatusPending, events[0].Status)
+	headers, err := events[0].HeaderMap()
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", headers["trace_id"])
+}
+
+func TestOutboxRelay_RelayOnce(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&OutboxEvent{}))
+	assert.NoError(t, EnqueueOutbox(db, &Event{Topic: "a"}, &Event{Topic: "b"}, &Event{Topic: "c"}))
+
+	publisher := &MemoryPublisher{}
+	relay := NewOutboxRelay(db, publisher, WithOutboxBatchSize(2))
+	n, err := relay.RelayOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	n, err = relay.RelayOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = relay.RelayOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	events := publisher.Events()
+	assert.Len(t, events, 3)
+	assert.Equal(t, []string{"a", "b", "c"}, []string{events[0].Topic, events[1].Topic, events[2].Topic})
+
+	var sent int64
+	assert.NoError(t, db.Model(&OutboxEvent{}).Where("status = ? AND sent_at IS NOT NULL", OutboxStatusSent).Count(&sent).Error)
+	assert.Equal(t, int64(3), sent)
+}
+
+func TestOutboxRelay_Retry(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&OutboxEvent{}))
+	assert.NoError(t, EnqueueOutbox(db, &Event{Topic: "a"}))
+
+	failing := PublisherFunc(func(context.Context, *OutboxEvent) error {
+		return errors.New("broker down")
+	})
+	relay := NewOutboxRelay(db, failing, WithOutboxMaxAttempts(2), WithOutboxBackoff(0, 0))
+	_, err := relay.RelayOnce(context.Background())
+	assert.NoError(t, err)
+
+	event := &OutboxEvent{}
+	assert.NoError(t, db.First(event).Error)
+	assert.Equal(t, OutboxStatusPending, event.Status)
+	assert.Equal(t, 1, event.Attempts)
+	assert.Equal(t, "broker down", event.LastError)
+
+	_, err = relay.RelayOnce(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, db.First(event).Error)
+	assert.Equal(t, OutboxStatusFailed, event.Status)
+	assert.Equal(t, 2, event.Attempts)
+
+	n, err := relay.RelayOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestOutboxRelay_RetryDelay(t *testing.T) {
+	relay := NewOutboxRelay(nil, nil, WithOutboxBackoff(time.Second, 5*time.Second))
+	assert.Equal(t, time.Second, relay.retryDelay(1))
+	assert.Equal(t, 2*time.Second, relay.retryDelay(2))
+	assert.Equal(t, 4*time.Second, relay.retryDelay(3))
+	assert.Equal(t, 5*time.Second, relay.retryDelay(4))
+}
+
+func TestOutboxRelay_Cleanup(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&OutboxEvent{}))
+	old := time.Now().Add(-48 * time.Hour)
+	assert.NoError(t, db.Create([]*OutboxEvent{
+		{Topic: "old", Status: OutboxStatusSent, SentAt: &old},
+		{Topic: "pending", Status: OutboxStatusPending},
+	}).Error)
+
+	relay := NewOutboxRelay(db, &MemoryPublisher{}, WithOutboxRetention(24*time.Hour))
+	n, err := relay.Cleanup(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestOutboxRelay_Run(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&OutboxEvent{}))
+	publisher := &MemoryPublisher{}
+	relay := NewOutboxRelay(db, publisher, WithOutboxPollInterval(10*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- relay.Run(ctx)
+	}()
+	assert.NoError(t, EnqueueOutbox(db, &Event{Topic: "a"}))
+	assert.Eventually(t, func() bool {
+		return len(publisher.Events()) == 1
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestOutboxRelay_RelayOnceCancel(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&OutboxEvent{}))
+	assert.NoError(t, EnqueueOutbox(db, &Event{Topic: "a"}, &Event{Topic: "b"}, &Event{Topic: "c"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var published []string
+	relay := NewOutboxRelay(db, PublisherFunc(func(_ context.Context, event *OutboxEvent) error {
+		published = append(published, event.Topic)
+		cancel()
+		return nil
+	}))
+	n, err := relay.RelayOnce(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"a"}, published)
+
+	// 已投递的事件状态已提交，不会重复投递
+	var sent, pending int64
+	assert.NoError(t, db.Model(&OutboxEvent{}).Where("status = ?", OutboxStatusSent).Count(&sent).Error)
+	assert.NoError(t, db.Model(&OutboxEvent{}).Where("status = ?", OutboxStatusPending).Count(&pending).Error)
+	assert.Equal(t, int64(1), sent)
+	assert.Equal(t, int64(2), pending)
+}
+
+func TestNewOutboxRelay_Defaults(t *testing.T) {
+	relay := NewOutboxRelay(nil, nil, WithOutboxBatchSize(0), WithOutboxPollInterval(-time.Second))
+	assert.Equal(t, defaultOutboxBatchSize, relay.batchSize)
+	assert.Equal(t, defaultOutboxPollInterval, relay.pollInterval)
+}
@@ -0,0 +1,113 @@
+package dbutil
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// WithTrashed 查询时包含已软删除的记录，配合 Scopes 使用:
+//
+//	db.Scopes(dbutil.WithTrashed).Find(&users)
+func WithTrashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// OnlyTrashed 仅查询已软删除的记录，配合 Scopes 使用:
+//
+//	db.Scopes(dbutil.OnlyTrashed).Find(&users)
+func OnlyTrashed(db *gorm.DB) *gorm.DB {
+	db = db.Unscoped()
+	column, err := deletedAtColumn(db, statementModel(db.Statement))
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+	return db.Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: nil})
+}
+
+// Restore 恢复已软删除的记录。
+// model 带主键时恢复该条记录，否则需要通过 db.Where 指定恢复条件，例如:
+//
+//	dbutil.Restore(tx.Where("id IN ?", ids), &User{})
+//
+// 返回值: 恢复的记录数
+func Restore(db *gorm.DB, model any) (int64, error) {
+	column, err := deletedAtColumn(db, model)
+	if err != nil {
+		return 0, err
+	}
+	// 既没有主键也没有查询条件时拒绝执行，避免误恢复整张表
+	if _, hasWhere := db.Statement.Clauses["WHERE"]; !hasWhere && !hasPrimaryKey(db, model) {
+		return 0, gorm.ErrMissingWhereClause
+	}
+	result := db.Unscoped().Model(model).
+		Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: nil}).
+		Update(column, nil)
+	return result.RowsAffected, result.Error
+}
+
+// PurgeSoftDeleted 物理删除软删除时间早于 days 天之前的记录。
+// 返回值: 删除的记录数
+func PurgeSoftDeleted(db *gorm.DB, model any, days int) (int64, error) {
+	if days < 0 {
+		return 0, fmt.Errorf("dbutil: invalid purge days %d", days)
+	}
+	column, err := deletedAtColumn(db, model)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+	result := db.Unscoped().
+		Where(clause.Lt{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: cutoff}).
+		Delete(model)
+	return result.RowsAffected, result.Error
+}
+
+// deletedAtColumn 查找模型中 gorm.DeletedAt 类型字段对应的列名
+func deletedAtColumn(db *gorm.DB, model any) (string, error) {
+	if model == nil {
+		return "", gorm.ErrModelValueRequired
+	}
+	sch, err := parseSchema(db, model)
+	if err != nil {
+		return "", err
+	}
+	if column := softDeleteColumn(sch); column != "" {
+		return column, nil
+	}
+	return "", fmt.Errorf("dbutil: model %s has no gorm.DeletedAt field", sch.Name)
+}
+
+// softDeleteColumn 模型的软删除列名，没有时返回空字符串
+func softDeleteColumn(sch *schema.Schema) string {
+	deletedAtType := reflect.TypeOf(gorm.DeletedAt{})
+	for _, field := range sch.Fields {
+		if field.FieldType == deletedAtType && field.DBName != "" {
+			return field.DBName
+		}
+	}
+	return ""
+}
+
+// hasPrimaryKey 判断模型的主键是否已赋值
+func hasPrimaryKey(db *gorm.DB, model any) bool {
+	sch, err := parseSchema(db, model)
+	if err != nil || sch.PrioritizedPrimaryField == nil {
+		return false
+	}
+	_, isZero := sch.PrioritizedPrimaryField.ValueOf(db.Statement.Context, reflect.ValueOf(model))
+	return !isZero
+}
+
+// statementModel 返回语句中的模型，未调用 Model 时使用查询目标
+func statementModel(stmt *gorm.Statement) any {
+	if stmt.Model != nil {
+		return stmt.Model
+	}
+	return stmt.Dest
+}
@@ -0,0 +1,89 @@
+package dbutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/lastares/claymore/dbutil/dbtest"
+)
+
+type article struct {
+	ID        int64
+	Title     string
+	DeletedAt gorm.DeletedAt
+}
+
+func TestSoftDeleteScopes(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&article{}))
+	articles := []*article{{Title: "a"}, {Title: "b"}, {Title: "c"}}
+	assert.NoError(t, db.Create(articles).Error)
+	assert.NoError(t, db.Delete(articles[1]).Error)
+
+	var list []article
+	assert.NoError(t, db.Find(&list).Error)
+	assert.Len(t, list, 2)
+
+	list = nil
+	assert.NoError(t, db.Scopes(WithTrashed).Find(&list).Error)
+	assert.Len(t, list, 3)
+
+	list = nil
+	assert.NoError(t, db.Scopes(OnlyTrashed).Find(&list).Error)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "b", list[0].Title)
+
+	var count int64
+	assert.NoError(t, db.Model(&article{}).Scopes(OnlyTrashed).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRestore(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&article{}))
+	articles := []*article{{Title: "a"}, {Title: "b"}, {Title: "c"}}
+	assert.NoError(t, db.Create(articles).Error)
+	assert.NoError(t, db.Delete(&article{}, []int64{articles[0].ID, articles[1].ID}).Error)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		n, err := Restore(tx, &article{ID: articles[0].ID})
+		assert.Equal(t, int64(1), n)
+		return err
+	})
+	assert.NoError(t, err)
+
+	var count int64
+	assert.NoError(t, db.Model(&article{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	n, err := Restore(db.Where("title = ?", "b"), &article{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, err = Restore(db, &article{})
+	assert.ErrorIs(t, err, gorm.ErrMissingWhereClause)
+}
+
+func TestPurgeSoftDeleted(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&article{}))
+	now := time.Now()
+	articles := []*article{
+		{Title: "old", DeletedAt: gorm.DeletedAt{Time: now.AddDate(0, 0, -40), Valid: true}},
+		{Title: "recent", DeletedAt: gorm.DeletedAt{Time: now.AddDate(0, 0, -1), Valid: true}},
+		{Title: "alive"},
+	}
+	assert.NoError(t, db.Create(articles).Error)
+
+	n, err := PurgeSoftDeleted(db, &article{}, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	var list []article
+	assert.NoError(t, db.Scopes(WithTrashed).Order("id").Find(&list).Error)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "recent", list[0].Title)
+
+	_, err = PurgeSoftDeleted(db, &account{}, 30)
+	assert.Error(t, err)
+}
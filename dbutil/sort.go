@@ -0,0 +1,44 @@
+package dbutil
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lastares/claymore/generalutil/pagination"
+	paginationpb "github.com/lastares/claymore/protobuf/pagination"
+)
+
+// OrderBy 按分页请求中的排序规则排序，配合 Scopes 使用:
+//
+//	fields := pagination.SortFields{"created_at": "", "name": "users.name"}
+//	db.Scopes(dbutil.OrderBy(req.GetPagination().GetSort(), fields)).Find(&users)
+//
+// 字段不在白名单 fields 中时查询返回 pagination.ErrInvalidSort。
+// 空值位置通过 "列 IS NULL" 排序实现，MySQL、PostgreSQL、SQLite 行为一致
+func OrderBy(sorts []*paginationpb.Sort, fields pagination.SortFields) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(sorts) == 0 {
+			return db
+		}
+		if err := fields.Validate(sorts); err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		columns := make([]clause.OrderByColumn, 0, len(sorts))
+		for _, sort := range sorts {
+			column, _ := fields.Column(sort.GetField())
+			switch sort.GetNulls() {
+			case paginationpb.Sort_NULLS_FIRST, paginationpb.Sort_NULLS_LAST:
+				columns = append(columns, clause.OrderByColumn{
+					Column: clause.Column{Name: db.Statement.Quote(column) + " IS NULL", Raw: true},
+					Desc:   sort.GetNulls() == paginationpb.Sort_NULLS_FIRST,
+				})
+			}
+			columns = append(columns, clause.OrderByColumn{
+				Column: clause.Column{Name: column},
+				Desc:   sort.GetDirection() == paginationpb.Sort_DIRECTION_DESC,
+			})
+		}
+		return db.Order(clause.OrderBy{Columns: columns})
+	}
+}
@@ -0,0 +1,55 @@
+package dbutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lastares/claymore/dbutil/dbtest"
+	"github.com/lastares/claymore/generalutil/pagination"
+)
+
+type product struct {
+	ID    int64
+	Name  string
+	Price *int64
+}
+
+func TestOrderBy(t *testing.T) {
+	db := dbtest.New(t, dbtest.WithModels(&product{}))
+	price := func(v int64) *int64 { return &v }
+	products := []*product{
+		{Name: "b", Price: price(20)},
+		{Name: "a", Price: nil},
+		{Name: "c", Price: price(10)},
+		{Name: "a", Price: price(30)},
+	}
+	require.NoError(t, db.Create(products).Error)
+	fields := pagination.SortFields{"name": "", "price": "products.price"}
+
+	names := func(sort string) []string {
+		sorts, err := pagination.ParseSort(sort)
+		require.NoError(t, err)
+		var list []product
+		// Scopes 在执行时才应用，id 作为最后的排序条件也放在 Scopes 中
+		byID := func(db *gorm.DB) *gorm.DB { return db.Order("id") }
+		require.NoError(t, db.Scopes(OrderBy(sorts, fields), byID).Find(&list).Error)
+		result := make([]string, 0, len(list))
+		for _, p := range list {
+			result = append(result, p.Name)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"a", "a", "b", "c"}, names("name"))
+	assert.Equal(t, []string{"a", "c", "b", "a"}, names("price:nulls_first"))
+	assert.Equal(t, []string{"c", "b", "a", "a"}, names("price:nulls_last"))
+	assert.Equal(t, []string{"a", "a", "b", "c"}, names("name,-price:nulls_last"))
+
+	sorts, err := pagination.ParseSort("-id")
+	require.NoError(t, err)
+	var list []product
+	err = db.Scopes(OrderBy(sorts, fields)).Find(&list).Error
+	assert.ErrorIs(t, err, pagination.ErrInvalidSort)
+}
@@ -0,0 +1,462 @@
+package dbutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+var (
+	// ErrTenantRequired context 中没有租户信息
+	ErrTenantRequired = errors.New("dbutil: tenant required")
+	// ErrTenantPoolExhausted 所有租户连接池的连接数之和已达上限，且没有可以回收的空闲连接池
+	ErrTenantPoolExhausted = errors.New("dbutil: tenant connection limit exceeded")
+	// ErrTenantManagerClosed 租户管理器已关闭
+	ErrTenantManagerClosed = errors.New("dbutil: tenant manager closed")
+)
+
+type tenantKey struct{}
+
+// WithTenant 将租户标识写入 context
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext 从 context 中读取租户标识
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// TenantResolver 根据租户标识返回该租户的数据库配置，
+// 按 schema 隔离时返回不同的 database，按实例隔离时返回不同的 host
+type TenantResolver interface {
+	Resolve(ctx context.Context, tenant string) (*conf.Data_Database, error)
+}
+
+// TenantResolverFunc 函数形式的 TenantResolver
+type TenantResolverFunc func(ctx context.Context, tenant string) (*conf.Data_Database, error)
+
+func (f TenantResolverFunc) Resolve(ctx context.Context, tenant string) (*conf.Data_Database, error) {
+	return f(ctx, tenant)
+}
+
+// TenantOpener 根据数据库配置创建 gorm 实例，默认为 New
+type TenantOpener func(dbConfig *conf.Data_Database, gormConfig gorm.Config) (*gorm.DB, error)
+
+// TenantOption 租户管理器配置
+type TenantOption func(m *TenantManager)
+
+// WithTenantGormConfig 创建租户连接时使用的 gorm 配置
+func WithTenantGormConfig(gormConfig gorm.Config) TenantOption {
+	return func(m *TenantManager) {
+		m.gormConfig = gormConfig
+	}
+}
+
+// WithTenantOpener 自定义创建 gorm 实例的方式
+func WithTenantOpener(opener TenantOpener) TenantOption {
+	return func(m *TenantManager) {
+		m.opener = opener
+	}
+}
+
+// WithTenantIdleTimeout 连接池空闲超过该时长后被关闭回收，默认 10 分钟，0 表示不回收
+func WithTenantIdleTimeout(timeout time.Duration) TenantOption {
+	return func(m *TenantManager) {
+		m.idleTimeout = timeout
+	}
+}
+
+// WithTenantMaxConnections 所有租户连接池的最大打开连接数之和，默认 0 表示不限制
+func WithTenantMaxConnections(total int) TenantOption {
+	return func(m *TenantManager) {
+		m.maxConnections = total
+	}
+}
+
+// WithTenantOpenTimeout 解析租户配置并创建连接池的超时时间，默认 30 秒。
+// 创建过程不随发起请求的 ctx 取消，避免一个请求取消导致同时等待该租户的其他请求失败
+func WithTenantOpenTimeout(timeout time.Duration) TenantOption {
+	return func(m *TenantManager) {
+		m.openTimeout = timeout
+	}
+}
+
+// WithTenantDefaultMaxOpen 租户配置未指定 max_open_connections 时每个连接池的最大连接数，默认 10
+func WithTenantDefaultMaxOpen(n int) TenantOption {
+	return func(m *TenantManager) {
+		m.defaultMaxOpen = n
+	}
+}
+
+type tenantPool struct {
+	db       *gorm.DB
+	maxOpen  int
+	lastUsed time.Time
+	// leases 通过 Acquire、DB、ForTenant 借出且尚未归还的数量，大于 0 时不会被回收
+	leases int
+	// removed 已从缓存中移除，等待借出的句柄全部归还后关闭并释放配额
+	removed bool
+}
+
+// TenantManager 多租户数据库管理器，按租户懒加载并缓存 *gorm.DB
+type TenantManager struct {
+	resolver       TenantResolver
+	opener         TenantOpener
+	gormConfig     gorm.Config
+	idleTimeout    time.Duration
+	openTimeout    time.Duration
+	maxConnections int
+	defaultMaxOpen int
+
+	mu     sync.Mutex
+	pools  map[string]*tenantPool
+	total  int
+	closed bool
+	group  singleflight.Group
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewTenantManager 创建多租户数据库管理器，配置了空闲回收时会启动后台回收协程，使用完毕需调用 Close
+func NewTenantManager(resolver TenantResolver, opts ...TenantOption) *TenantManager {
+	m := &TenantManager{
+		resolver:       resolver,
+		opener:         New,
+		idleTimeout:    10 * time.Minute,
+		openTimeout:    30 * time.Second,
+		defaultMaxOpen: 10,
+		pools:          make(map[string]*tenantPool),
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.openTimeout <= 0 {
+		m.openTimeout = 30 * time.Second
+	}
+	if m.idleTimeout > 0 {
+		go m.janitor()
+	} else {
+		close(m.done)
+	}
+	return m
+}
+
+// DB 返回 context 中租户对应的 gorm 实例，已绑定 ctx，与 ForTenant 相同
+func (m *TenantManager) DB(ctx context.Context) (*gorm.DB, error) {
+	tenant, ok := TenantFromContext(ctx)
+	if !ok {
+		return nil, ErrTenantRequired
+	}
+	return m.ForTenant(ctx, tenant)
+}
+
+// ForTenant 返回指定租户的 gorm 实例，已绑定 ctx。连接池借出到 ctx 结束，期间不会被回收或关闭，
+// 返回的实例只应在本次请求中使用，不能跨请求保存。
+// ctx 不会结束（例如 context.Background()）时不借出，连接池随时可能被回收，需要长时间持有时使用 Acquire
+func (m *TenantManager) ForTenant(ctx context.Context, tenant string) (*gorm.DB, error) {
+	lease := ctx.Done() != nil
+	pool, err := m.pool(ctx, tenant, lease)
+	if err != nil {
+		return nil, err
+	}
+	if lease {
+		context.AfterFunc(ctx, func() {
+			m.release(pool)
+		})
+	}
+	return pool.db.WithContext(ctx), nil
+}
+
+// Acquire 借出指定租户的 gorm 实例，归还前连接池不会被回收或关闭，用于在多次调用之间持有实例，
+// 例如后台任务。使用完毕必须调用 release，release 可以重复调用:
+//
+//	db, release, err := m.Acquire(ctx, tenant)
+//	if err != nil {
+//		return err
+//	}
+//	defer release()
+func (m *TenantManager) Acquire(ctx context.Context, tenant string) (*gorm.DB, func(), error) {
+	pool, err := m.pool(ctx, tenant, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			m.release(pool)
+		})
+	}
+	return pool.db.WithContext(ctx), release, nil
+}
+
+// pool 返回租户的连接池，不存在时创建，lease 为 true 时同时借出
+func (m *TenantManager) pool(ctx context.Context, tenant string, lease bool) (*tenantPool, error) {
+	for {
+		pool, ok, err := m.cached(tenant, lease)
+		if err != nil || ok {
+			return pool, err
+		}
+		// 同一租户并发首次访问时只创建一个连接池，创建过程不随单个请求的 ctx 取消，
+		// 每个等待方只受自己的 ctx 控制
+		ch := m.group.DoChan(tenant, func() (any, error) {
+			if _, ok, err := m.cached(tenant, false); err != nil || ok {
+				return nil, err
+			}
+			openCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.openTimeout)
+			defer cancel()
+			return nil, m.open(openCtx, tenant)
+		})
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case result := <-ch:
+			if result.Err != nil {
+				return nil, result.Err
+			}
+		}
+		// 创建完成后重新从缓存中读取，期间被回收时重新创建
+	}
+}
+
+// cached 返回已缓存的连接池并刷新最近使用时间，lease 为 true 时同时借出
+func (m *TenantManager) cached(tenant string, lease bool) (*tenantPool, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, false, ErrTenantManagerClosed
+	}
+	pool, ok := m.pools[tenant]
+	if !ok {
+		return nil, false, nil
+	}
+	pool.lastUsed = time.Now()
+	if lease {
+		pool.leases++
+	}
+	return pool, true, nil
+}
+
+// release 归还借出的连接池，连接池已被移除且没有其他借出时关闭并释放配额
+func (m *TenantManager) release(pool *tenantPool) {
+	m.mu.Lock()
+	pool.leases--
+	pool.lastUsed = time.Now()
+	closing := pool.removed && pool.leases == 0
+	if closing {
+		m.total -= pool.maxOpen
+	}
+	m.mu.Unlock()
+	if closing {
+		closeDB(pool.db)
+	}
+}
+
+// open 解析租户配置并创建连接池
+func (m *TenantManager) open(ctx context.Context, tenant string) error {
+	dbConfig, err := m.resolver.Resolve(ctx, tenant)
+	if err != nil {
+		return fmt.Errorf("dbutil: resolve tenant %s: %w", tenant, err)
+	}
+	maxOpen := int(dbConfig.GetMaxOpenConnections())
+	if maxOpen <= 0 {
+		maxOpen = m.defaultMaxOpen
+	}
+	if m.maxConnections > 0 && maxOpen > m.maxConnections {
+		maxOpen = m.maxConnections
+	}
+	// 先预留连接配额，避免并发创建不同租户时超出上限
+	if err = m.reserve(maxOpen); err != nil {
+		return err
+	}
+	db, err := m.opener(dbConfig, m.gormConfig)
+	if err == nil {
+		err = setMaxOpen(db, maxOpen, int(dbConfig.GetMaxIdleConnections()))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil || m.closed {
+		m.total -= maxOpen
+		if db != nil {
+			closeDB(db)
+		}
+		if err == nil {
+			err = ErrTenantManagerClosed
+		}
+		return err
+	}
+	m.pools[tenant] = &tenantPool{db: db, maxOpen: maxOpen, lastUsed: time.Now()}
+	return nil
+}
+
+// reserve 预留 n 个连接的配额，不足时按最近最少使用回收空闲连接池
+func (m *TenantManager) reserve(n int) error {
+	m.mu.Lock()
+	var evicted []*gorm.DB
+	for m.maxConnections > 0 && m.total+n > m.maxConnections {
+		tenant := m.leastRecentlyUsedIdle()
+		if tenant == "" {
+			m.mu.Unlock()
+			closeAll(evicted)
+			return ErrTenantPoolExhausted
+		}
+		evicted = append(evicted, m.remove(tenant))
+	}
+	m.total += n
+	m.mu.Unlock()
+	closeAll(evicted)
+	return nil
+}
+
+// leastRecentlyUsedIdle 返回没有借出、没有正在使用的连接且最久未使用的租户
+func (m *TenantManager) leastRecentlyUsedIdle() string {
+	var (
+		candidate string
+		oldest    time.Time
+	)
+	for tenant, pool := range m.pools {
+		if pool.leases > 0 || inUse(pool.db) {
+			continue
+		}
+		if candidate == "" || pool.lastUsed.Before(oldest) {
+			candidate, oldest = tenant, pool.lastUsed
+		}
+	}
+	return candidate
+}
+
+// remove 从缓存中移除租户连接池并释放配额，调用方负责关闭返回的连接池。
+// 连接池仍有借出时返回 nil，连接仍可能打开，配额保留到最后一次归还时释放
+func (m *TenantManager) remove(tenant string) *gorm.DB {
+	pool := m.pools[tenant]
+	delete(m.pools, tenant)
+	pool.removed = true
+	if pool.leases > 0 {
+		return nil
+	}
+	m.total -= pool.maxOpen
+	return pool.db
+}
+
+// Evict 移除指定租户的连接池，下次访问时重新创建。没有借出时立即关闭，否则在全部归还后关闭
+func (m *TenantManager) Evict(tenant string) {
+	m.mu.Lock()
+	if _, ok := m.pools[tenant]; !ok {
+		m.mu.Unlock()
+		return
+	}
+	db := m.remove(tenant)
+	m.mu.Unlock()
+	closeDB(db)
+}
+
+// EvictIdle 关闭空闲时间超过 idleTimeout 的连接池，返回回收的数量
+func (m *TenantManager) EvictIdle() int {
+	if m.idleTimeout <= 0 {
+		return 0
+	}
+	m.mu.Lock()
+	var evicted []*gorm.DB
+	deadline := time.Now().Add(-m.idleTimeout)
+	for tenant, pool := range m.pools {
+		if pool.lastUsed.Before(deadline) && pool.leases == 0 && !inUse(pool.db) {
+			evicted = append(evicted, m.remove(tenant))
+		}
+	}
+	m.mu.Unlock()
+	closeAll(evicted)
+	return len(evicted)
+}
+
+// Tenants 返回当前已缓存连接池的租户
+func (m *TenantManager) Tenants() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tenants := make([]string, 0, len(m.pools))
+	for tenant := range m.pools {
+		tenants = append(tenants, tenant)
+	}
+	return tenants
+}
+
+// Connections 返回所有租户连接池的最大打开连接数之和，包含已移除但尚未归还的连接池
+func (m *TenantManager) Connections() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.total
+}
+
+// Close 停止后台回收并关闭所有连接池，借出中的连接池在归还后关闭
+func (m *TenantManager) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	close(m.stop)
+	var evicted []*gorm.DB
+	for tenant := range m.pools {
+		evicted = append(evicted, m.remove(tenant))
+	}
+	m.mu.Unlock()
+	<-m.done
+	closeAll(evicted)
+}
+
+func (m *TenantManager) janitor() {
+	defer close(m.done)
+	ticker := time.NewTicker(max(m.idleTimeout/2, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.EvictIdle()
+		}
+	}
+}
+
+func setMaxOpen(db *gorm.DB, maxOpen, maxIdle int) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if maxIdle <= 0 || maxIdle > maxOpen {
+		maxIdle = maxOpen
+	}
+	sqlDB.SetMaxOpenConns(maxOpen)
+	sqlDB.SetMaxIdleConns(maxIdle)
+	return nil
+}
+
+func inUse(db *gorm.DB) bool {
+	sqlDB, err := db.DB()
+	return err == nil && sqlDB.Stats().InUse > 0
+}
+
+func closeDB(db *gorm.DB) {
+	if db == nil {
+		return
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+}
+
+func closeAll(dbs []*gorm.DB) {
+	for _, db := range dbs {
+		closeDB(db)
+	}
+}
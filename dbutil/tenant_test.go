@@ -0,0 +1,227 @@
+package dbutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+func newTestTenantManager(t *testing.T, opts ...TenantOption) (*TenantManager, *int32) {
+	t.Helper()
+	var opened int32
+	resolver := TenantResolverFunc(func(_ context.Context, tenant string) (*conf.Data_Database, error) {
+		if tenant == "unknown" {
+			return nil, errors.New("tenant not found")
+		}
+		return &conf.Data_Database{
+			Driver:             "sqlite",
+			Database:           "file:" + t.Name() + "_" + tenant + "?mode=memory&cache=shared",
+			MaxOpenConnections: 2,
+		}, nil
+	})
+	opener := func(dbConfig *conf.Data_Database, gormConfig gorm.Config) (*gorm.DB, error) {
+		atomic.AddInt32(&opened, 1)
+		return openTestTenant(dbConfig, gormConfig)
+	}
+	m := NewTenantManager(resolver, append([]TenantOption{WithTenantOpener(opener)}, opts...)...)
+	t.Cleanup(m.Close)
+	return m, &opened
+}
+
+func openTestTenant(dbConfig *conf.Data_Database, gormConfig gorm.Config) (*gorm.DB, error) {
+	dsn, err := BuildDSN(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	gormConfig.Logger = logger.Default.LogMode(logger.Silent)
+	return gorm.Open(sqlite.Open(dsn), &gormConfig)
+}
+
+func TestTenantManager_DB(t *testing.T) {
+	m, opened := newTestTenantManager(t)
+
+	_, err := m.DB(context.Background())
+	assert.ErrorIs(t, err, ErrTenantRequired)
+
+	ctxA := WithTenant(context.Background(), "a")
+	ctxB := WithTenant(context.Background(), "b")
+	dbA, err := m.DB(ctxA)
+	assert.NoError(t, err)
+	assert.NoError(t, dbA.AutoMigrate(&account{}))
+	assert.NoError(t, dbA.Create(&account{Name: "a"}).Error)
+
+	dbB, err := m.DB(ctxB)
+	assert.NoError(t, err)
+	assert.NoError(t, dbB.AutoMigrate(&account{}))
+	var count int64
+	assert.NoError(t, dbB.Model(&account{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	dbA, err = m.DB(ctxA)
+	assert.NoError(t, err)
+	assert.NoError(t, dbA.Model(&account{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, int32(2), atomic.LoadInt32(opened))
+	assert.Equal(t, 4, m.Connections())
+
+	_, err = m.ForTenant(context.Background(), "unknown")
+	assert.Error(t, err)
+}
+
+func TestTenantManager_Concurrent(t *testing.T) {
+	m, opened := newTestTenantManager(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := m.ForTenant(context.Background(), "a")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(opened))
+}
+
+func TestTenantManager_MaxConnections(t *testing.T) {
+	m, _ := newTestTenantManager(t, WithTenantMaxConnections(4))
+	ctx := context.Background()
+	for _, tenant := range []string{"a", "b", "c"} {
+		_, err := m.ForTenant(ctx, tenant)
+		assert.NoError(t, err)
+		time.Sleep(time.Millisecond)
+	}
+	// 超出上限时回收最久未使用的租户 a
+	assert.ElementsMatch(t, []string{"b", "c"}, m.Tenants())
+	assert.Equal(t, 4, m.Connections())
+
+	// 所有连接池都在使用中时无法回收
+	dbB, _ := m.ForTenant(ctx, "b")
+	dbC, _ := m.ForTenant(ctx, "c")
+	txB := dbB.Begin()
+	txC := dbC.Begin()
+	_, err := m.ForTenant(ctx, "d")
+	assert.ErrorIs(t, err, ErrTenantPoolExhausted)
+	txB.Rollback()
+	txC.Rollback()
+}
+
+func TestTenantManager_EvictIdle(t *testing.T) {
+	m, opened := newTestTenantManager(t, WithTenantIdleTimeout(20*time.Millisecond))
+	ctx := context.Background()
+	_, err := m.ForTenant(ctx, "a")
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(m.Tenants()) == 0
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, m.Connections())
+
+	_, err = m.ForTenant(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(opened))
+
+	m.Evict("a")
+	assert.Empty(t, m.Tenants())
+	m.Close()
+	_, err = m.ForTenant(ctx, "a")
+	assert.ErrorIs(t, err, ErrTenantManagerClosed)
+}
+
+func TestTenantManager_Acquire(t *testing.T) {
+	m, _ := newTestTenantManager(t, WithTenantMaxConnections(2), WithTenantIdleTimeout(20*time.Millisecond))
+	ctx := context.Background()
+	db, release, err := m.Acquire(ctx, "a")
+	assert.NoError(t, err)
+
+	// 借出期间不会被空闲回收或容量回收
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, m.EvictIdle())
+	_, err = m.ForTenant(ctx, "b")
+	assert.ErrorIs(t, err, ErrTenantPoolExhausted)
+	assert.NoError(t, db.Exec("SELECT 1").Error)
+
+	// 显式移除后仍可使用，归还后关闭
+	m.Evict("a")
+	assert.Empty(t, m.Tenants())
+	assert.NoError(t, db.Exec("SELECT 1").Error)
+	release()
+	release()
+	assert.Error(t, db.Exec("SELECT 1").Error)
+
+	_, err = m.ForTenant(ctx, "b")
+	assert.NoError(t, err)
+}
+
+func TestTenantManager_ForTenantLease(t *testing.T) {
+	m, _ := newTestTenantManager(t, WithTenantMaxConnections(2), WithTenantIdleTimeout(20*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	db, err := m.ForTenant(ctx, "a")
+	assert.NoError(t, err)
+
+	// ctx 结束前不会被空闲回收或容量回收
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, m.EvictIdle())
+	_, err = m.ForTenant(context.Background(), "b")
+	assert.ErrorIs(t, err, ErrTenantPoolExhausted)
+	assert.NoError(t, db.Exec("SELECT 1").Error)
+
+	// 显式移除后配额保留到归还，不会超出上限
+	m.Evict("a")
+	assert.Empty(t, m.Tenants())
+	assert.Equal(t, 2, m.Connections())
+	_, err = m.ForTenant(context.Background(), "b")
+	assert.ErrorIs(t, err, ErrTenantPoolExhausted)
+	assert.NoError(t, db.Exec("SELECT 1").Error)
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		return m.Connections() == 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Error(t, db.Exec("SELECT 1").Error)
+	_, err = m.ForTenant(context.Background(), "b")
+	assert.NoError(t, err)
+}
+
+func TestTenantManager_OpenDetached(t *testing.T) {
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	resolver := TenantResolverFunc(func(ctx context.Context, tenant string) (*conf.Data_Database, error) {
+		close(started)
+		<-proceed
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return &conf.Data_Database{Driver: "sqlite", Database: "file:" + t.Name() + "?mode=memory&cache=shared"}, nil
+	})
+	m := NewTenantManager(resolver, WithTenantOpener(openTestTenant))
+	t.Cleanup(m.Close)
+
+	// 首个请求取消后只有自己返回，创建过程继续，其他等待方拿到连接池
+	first, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := m.ForTenant(first, "a")
+		errs <- err
+	}()
+	<-started
+	second := make(chan error, 1)
+	go func() {
+		_, err := m.ForTenant(context.Background(), "a")
+		second <- err
+	}()
+	cancel()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+	close(proceed)
+	assert.NoError(t, <-second)
+	assert.Equal(t, []string{"a"}, m.Tenants())
+}
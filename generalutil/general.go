@@ -4,11 +4,25 @@ import (
 	"bytes"
 	"encoding/json"
 	"log"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/lastares/claymore/protobuf/conf"
 )
 
-func PrettyPrintStruct(obj interface{}) {
+// PrettyPrintStruct 格式化打印结构体，默认脱敏：conf 包 proto 消息中标记了 debug_redact 的字段，
+// 以及通过 conf.MarkSensitive 登记的密钥（例如配置加载时解析出的 ${env:...}）都会被替换为 conf.RedactedValue。
+// redact 在默认脱敏之后依次作用于格式化后的文本，用于额外的脱敏
+func PrettyPrintStruct(obj interface{}, redact ...func(s string) string) {
+	if m, ok := obj.(proto.Message); ok {
+		obj = conf.Redact(m)
+	}
 	jsonStr, _ := json.Marshal(obj)
 	var buf bytes.Buffer
 	json.Indent(&buf, jsonStr, "", "\t")
-	log.Printf("pretty struct output=%v\n", buf.String())
+	output := conf.RedactSecrets(buf.String())
+	for _, fn := range redact {
+		output = fn(output)
+	}
+	log.Printf("pretty struct output=%v\n", output)
 }
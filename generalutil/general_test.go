@@ -1,7 +1,15 @@
 package generalutil
 
 import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lastares/claymore/protobuf/conf"
 )
 
 func TestBeautifyPrintStruct(t *testing.T) {
@@ -13,3 +21,35 @@ func TestBeautifyPrintStruct(t *testing.T) {
 	u := User{Name: "John", Age: 30, Address: []int{1, 2, 3, 4}}
 	PrettyPrintStruct(u)
 }
+
+func TestPrettyPrintStructRedact(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	redact := func(s string) string {
+		return strings.ReplaceAll(s, "plain-password", "******")
+	}
+	PrettyPrintStruct(struct{ User, Password string }{User: "root", Password: "plain-password"}, redact)
+
+	output := buf.String()
+	assert.NotContains(t, output, "plain-password")
+	assert.Contains(t, output, "******")
+	assert.Contains(t, output, "root")
+}
+
+func TestPrettyPrintStructDefaultRedact(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	conf.MarkSensitive("general-resolved-secret")
+	PrettyPrintStruct(struct{ User, Token string }{User: "root", Token: "general-resolved-secret"})
+	PrettyPrintStruct(&conf.Data_Database{User: "root", Password: "plain-password"})
+
+	output := buf.String()
+	assert.NotContains(t, output, "general-resolved-secret")
+	assert.NotContains(t, output, "plain-password")
+	assert.Contains(t, output, conf.RedactedValue)
+	assert.Contains(t, output, "root")
+}
@@ -0,0 +1,166 @@
+package pagination
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// KeyStyle JSON 字段命名风格
+type KeyStyle int
+
+const (
+	// SnakeCase 例如 page_size，与 proto 字段名一致
+	SnakeCase KeyStyle = iota
+	// CamelCase 例如 pageSize，与 protojson 默认输出一致
+	CamelCase
+)
+
+// Envelope 分页响应的 JSON 信封格式:
+//
+//	{"list": [...], "pagination": {"page": 1, "page_size": 10, ...}}
+//
+// 分页字段名以 snake_case 表示，按 KeyStyle 输出
+type Envelope struct {
+	// KeyStyle 分页字段命名风格，同时决定 ProtoJSON 模式下列表中 proto 消息的字段命名
+	KeyStyle KeyStyle
+	// ListKey 列表字段名，默认 list
+	ListKey string
+	// MetaKey 分页信息字段名，默认 pagination
+	MetaKey string
+	// OmitEmpty 省略零值分页字段，例如第一页的 prev
+	OmitEmpty bool
+	// Omit 始终省略的分页字段，例如 next_cursor、out_of_range
+	Omit []string
+	// ProtoJSON 输出与 protojson 兼容：total 以字符串表示，列表中的 proto 消息使用 protojson 编码，
+	// 不输出 PaginationReply 中没有的 out_of_range，分页信息可以直接由 protojson 解析为 PaginationReply
+	ProtoJSON bool
+}
+
+// DefaultEnvelope Paginator.Encode 使用的信封格式，服务启动时统一设置，保证各接口格式一致
+var DefaultEnvelope = Envelope{ListKey: "list", MetaKey: "pagination"}
+
+// Encode 使用 DefaultEnvelope 编码分页结果
+func (p *Paginator[T]) Encode() ([]byte, error) {
+	return DefaultEnvelope.Encode(p.List, p.Pagination)
+}
+
+// MarshalJSON 实现 json.Marshaler，使用 DefaultEnvelope 编码，
+// 直接 json.Marshal 或作为响应结构体字段时输出与 Encode 一致
+func (p *Paginator[T]) MarshalJSON() ([]byte, error) {
+	return p.Encode()
+}
+
+// metaField 分页字段，name 为 snake_case 字段名
+type metaField struct {
+	name  string
+	value any
+	zero  bool
+}
+
+// Encode 将列表和分页信息编码为信封格式，nil 切片编码为 []
+func (e Envelope) Encode(list any, pb *Builder) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeKey(&buf, cmp.Or(e.ListKey, "list"))
+	data, err := e.encodeList(list)
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(data)
+	buf.WriteByte(',')
+	writeKey(&buf, cmp.Or(e.MetaKey, "pagination"))
+	buf.WriteByte('{')
+	first := true
+	for _, field := range e.metaFields(pb) {
+		if (e.OmitEmpty && field.zero) || slices.Contains(e.Omit, field.name) {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		writeKey(&buf, e.key(field.name))
+		data, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	buf.WriteString("}}")
+	return buf.Bytes(), nil
+}
+
+func (e Envelope) metaFields(pb *Builder) []metaField {
+	var total any = pb.Total
+	if e.ProtoJSON {
+		// protojson 将 int64 编码为字符串
+		total = strconv.Itoa(pb.Total)
+	}
+	fields := []metaField{
+		{"page", pb.Page, pb.Page == 0},
+		{"page_size", pb.PageSize, pb.PageSize == 0},
+		{"total", total, pb.Total == 0},
+		{"total_pages", pb.TotalPages, pb.TotalPages == 0},
+		{"next", pb.Next, pb.Next == 0},
+		{"prev", pb.Prev, pb.Prev == 0},
+		{"has_more", pb.HasMore, !pb.HasMore},
+		{"next_cursor", pb.NextCursor, pb.NextCursor == ""},
+	}
+	if !e.ProtoJSON {
+		fields = append(fields, metaField{"out_of_range", pb.OutOfRange, !pb.OutOfRange})
+	}
+	return fields
+}
+
+func (e Envelope) encodeList(list any) ([]byte, error) {
+	rv := reflect.ValueOf(list)
+	if !rv.IsValid() || (rv.Kind() == reflect.Slice && rv.IsNil()) {
+		return []byte("[]"), nil
+	}
+	if !e.ProtoJSON || rv.Kind() != reflect.Slice || !rv.Type().Elem().Implements(protoMessageType) {
+		return json.Marshal(list)
+	}
+	opts := protojson.MarshalOptions{UseProtoNames: e.KeyStyle == SnakeCase}
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		data, err := opts.Marshal(rv.Index(i).Interface().(proto.Message))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+var protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+// key 按命名风格转换字段名
+func (e Envelope) key(name string) string {
+	if e.KeyStyle != CamelCase {
+		return name
+	}
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
+
+func writeKey(buf *bytes.Buffer, key string) {
+	data, _ := json.Marshal(key)
+	buf.Write(data)
+	buf.WriteByte(':')
+}
@@ -0,0 +1,103 @@
+package pagination
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/lastares/claymore/protobuf/pagination"
+)
+
+func TestEnvelopeEncode(t *testing.T) {
+	builder := NewPaginationBuilder(10).WithPagination(&pagination.Pagination{Page: 1, PageSize: 5}).Build()
+	tests := []struct {
+		name     string
+		envelope Envelope
+		list     any
+		want     string
+	}{
+		{
+			name:     "default",
+			envelope: DefaultEnvelope,
+			list:     []int{1, 2},
+			want:     `{"list":[1,2],"pagination":{"page":1,"page_size":5,"total":10,"total_pages":2,"next":2,"prev":0,"has_more":true,"next_cursor":"","out_of_range":false}}`,
+		},
+		{
+			name:     "camel case with custom keys",
+			envelope: Envelope{KeyStyle: CamelCase, ListKey: "items", MetaKey: "meta", OmitEmpty: true},
+			list:     []string{"a"},
+			want:     `{"items":["a"],"meta":{"page":1,"pageSize":5,"total":10,"totalPages":2,"next":2,"hasMore":true}}`,
+		},
+		{
+			name:     "omit fields and nil list",
+			envelope: Envelope{Omit: []string{"next", "prev", "next_cursor", "out_of_range", "total_pages"}},
+			list:     []int(nil),
+			want:     `{"list":[],"pagination":{"page":1,"page_size":5,"total":10,"has_more":true}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.envelope.Encode(tt.list, builder)
+			if err != nil {
+				t.Fatalf("Encode() err = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Encode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvelopeProtoJSON(t *testing.T) {
+	builder := NewPaginationBuilder(3).WithPagination(&pagination.Pagination{Page: 1, PageSize: 2}).Build()
+	list := []*pagination.Sort{{Field: "created_at", Direction: pagination.Sort_DIRECTION_DESC}}
+	for _, style := range []KeyStyle{SnakeCase, CamelCase} {
+		envelope := Envelope{KeyStyle: style, ProtoJSON: true}
+		data, err := envelope.Encode(list, builder)
+		if err != nil {
+			t.Fatalf("Encode() err = %v", err)
+		}
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			t.Fatalf("Encode() = %s, invalid json: %v", data, err)
+		}
+		// 分页信息可以直接由 protojson 解析
+		reply := &pagination.PaginationReply{}
+		if err := protojson.Unmarshal(raw["pagination"], reply); err != nil {
+			t.Fatalf("protojson.Unmarshal(%s) err = %v", raw["pagination"], err)
+		}
+		if !proto.Equal(reply, builder.Reply()) {
+			t.Errorf("protojson.Unmarshal() = %v, want %v", reply, builder.Reply())
+		}
+		var sorts []json.RawMessage
+		_ = json.Unmarshal(raw["list"], &sorts)
+		sort := &pagination.Sort{}
+		if len(sorts) != 1 || protojson.Unmarshal(sorts[0], sort) != nil || !proto.Equal(sort, list[0]) {
+			t.Errorf("Encode() list = %s", raw["list"])
+		}
+	}
+}
+
+func TestPaginatorEncode(t *testing.T) {
+	p := NewPaginator(1, []string{"a"}, &pagination.Pagination{})
+	got, err := p.Encode()
+	if err != nil {
+		t.Fatalf("Encode() err = %v", err)
+	}
+	want := `{"list":["a"],"pagination":{"page":1,"page_size":10,"total":1,"total_pages":1,"next":0,"prev":0,"has_more":false,"next_cursor":"","out_of_range":false}}`
+	if string(got) != want {
+		t.Errorf("Encode() = %s, want %s", got, want)
+	}
+
+	got, err = json.Marshal(p)
+	if err != nil || string(got) != want {
+		t.Errorf("json.Marshal() = %s, err = %v, want %s", got, err, want)
+	}
+	// 作为字段嵌套时同样使用信封格式
+	got, err = json.Marshal(map[string]any{"data": p})
+	if err != nil || string(got) != `{"data":`+want+`}` {
+		t.Errorf("json.Marshal() = %s, err = %v", got, err)
+	}
+}
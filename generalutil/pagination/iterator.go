@@ -0,0 +1,156 @@
+package pagination
+
+import (
+	"context"
+)
+
+// PageFetcher 按页码（从 1 开始）读取一页数据，返回当前页数据和总数，总数未知时返回 -1
+type PageFetcher[T any] func(ctx context.Context, page, pageSize int) (items []T, total int, err error)
+
+type pageResult[T any] struct {
+	items []T
+	total int
+	err   error
+}
+
+// PageIterator 逐页遍历分页数据源，处理当前页时在后台预取下一页，适用于导出完整数据集:
+//
+//	it := pagination.NewPageIterator(ctx, 500, fetchOrders)
+//	defer it.Close()
+//	for it.Next() {
+//		write(it.Items())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// 总数已知时读取到最后一页结束，总数未知（-1）时读取到不足一页或空页结束
+type PageIterator[T any] struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	fetch    PageFetcher[T]
+	pageSize int
+
+	page    int
+	items   []T
+	total   int
+	err     error
+	done    bool
+	pending chan pageResult[T]
+}
+
+// NewPageIterator 创建分页迭代器，pageSize 小于 1 时使用默认值 10
+func NewPageIterator[T any](ctx context.Context, pageSize int, fetch PageFetcher[T]) *PageIterator[T] {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &PageIterator[T]{
+		ctx:      ctx,
+		cancel:   cancel,
+		fetch:    fetch,
+		pageSize: pageSize,
+		total:    -1,
+	}
+}
+
+// start 在后台读取指定页
+func (it *PageIterator[T]) start(page int) chan pageResult[T] {
+	ch := make(chan pageResult[T], 1)
+	go func() {
+		items, total, err := it.fetch(it.ctx, page, it.pageSize)
+		ch <- pageResult[T]{items: items, total: total, err: err}
+	}()
+	return ch
+}
+
+// Next 前进到下一页，没有更多数据或出错时返回 false
+func (it *PageIterator[T]) Next() bool {
+	if it.done {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.finish(err)
+		return false
+	}
+	if it.pending == nil {
+		it.pending = it.start(it.page + 1)
+	}
+	var result pageResult[T]
+	select {
+	case result = <-it.pending:
+	case <-it.ctx.Done():
+		result.err = it.ctx.Err()
+	}
+	it.pending = nil
+	if result.err != nil {
+		it.finish(result.err)
+		return false
+	}
+	if len(result.items) == 0 {
+		it.finish(nil)
+		return false
+	}
+	it.page++
+	it.items, it.total = result.items, result.total
+	if it.hasNext() {
+		// 调用方处理当前页时预取下一页
+		it.pending = it.start(it.page + 1)
+	} else {
+		it.done = true
+		it.cancel()
+	}
+	return true
+}
+
+func (it *PageIterator[T]) hasNext() bool {
+	if it.total >= 0 {
+		return it.page*it.pageSize < it.total
+	}
+	return len(it.items) >= it.pageSize
+}
+
+func (it *PageIterator[T]) finish(err error) {
+	it.done = true
+	it.items = nil
+	it.err = err
+	it.cancel()
+}
+
+// Items 当前页数据
+func (it *PageIterator[T]) Items() []T {
+	return it.items
+}
+
+// Page 当前页码，从 1 开始
+func (it *PageIterator[T]) Page() int {
+	return it.page
+}
+
+// Total 数据源返回的总数，未知时为 -1
+func (it *PageIterator[T]) Total() int {
+	return it.total
+}
+
+// Err 遍历过程中的错误
+func (it *PageIterator[T]) Err() error {
+	return it.err
+}
+
+// Close 停止遍历并取消正在进行的预取，遍历未结束时应调用
+func (it *PageIterator[T]) Close() {
+	it.done = true
+	it.cancel()
+}
+
+// ForEachPage 遍历所有页并对每页调用 fn，fn 返回错误时停止遍历
+func ForEachPage[T any](ctx context.Context, pageSize int, fetch PageFetcher[T], fn func(items []T) error) error {
+	it := NewPageIterator(ctx, pageSize, fetch)
+	defer it.Close()
+	for it.Next() {
+		if err := fn(it.Items()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
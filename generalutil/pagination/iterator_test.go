@@ -0,0 +1,158 @@
+package pagination
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lastares/claymore/protobuf/pagination"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7}
+	tests := []struct {
+		page, pageSize int32
+		want           []int
+		wantNext       int
+	}{
+		{1, 3, []int{1, 2, 3}, 2},
+		{3, 3, []int{7}, 0},
+		{0, 0, []int{1, 2, 3, 4, 5, 6, 7}, 0},
+		{4, 3, []int{}, 0},
+	}
+	for _, tt := range tests {
+		got := Paginate(items, &pagination.Pagination{Page: tt.page, PageSize: tt.pageSize})
+		if len(got.List) != len(tt.want) {
+			t.Errorf("Paginate(%d, %d) = %v, want %v", tt.page, tt.pageSize, got.List, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if got.List[i] != tt.want[i] {
+				t.Errorf("Paginate(%d, %d) = %v, want %v", tt.page, tt.pageSize, got.List, tt.want)
+				break
+			}
+		}
+		if got.Pagination.Next != tt.wantNext || got.Pagination.Total != len(items) {
+			t.Errorf("Paginate(%d, %d) pagination = %+v", tt.page, tt.pageSize, got.Pagination)
+		}
+	}
+
+	// 追加元素不会覆盖原切片
+	page := Paginate(items, &pagination.Pagination{Page: 1, PageSize: 3}).List
+	_ = append(page, 100)
+	if items[3] != 4 {
+		t.Errorf("append to page modified source slice: %v", items)
+	}
+}
+
+func fetchRange(n int, calls *atomic.Int32) PageFetcher[int] {
+	return func(ctx context.Context, page, pageSize int) ([]int, int, error) {
+		calls.Add(1)
+		var items []int
+		for i := (page-1)*pageSize + 1; i <= min(page*pageSize, n); i++ {
+			items = append(items, i)
+		}
+		return items, n, nil
+	}
+}
+
+func TestPageIterator(t *testing.T) {
+	var calls atomic.Int32
+	it := NewPageIterator(context.Background(), 3, fetchRange(7, &calls))
+	defer it.Close()
+	var all []int
+	pages := 0
+	for it.Next() {
+		pages++
+		if it.Page() != pages || it.Total() != 7 {
+			t.Errorf("Page() = %d, Total() = %d", it.Page(), it.Total())
+		}
+		all = append(all, it.Items()...)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if pages != 3 || len(all) != 7 || all[6] != 7 {
+		t.Errorf("iterated %d pages: %v", pages, all)
+	}
+	// 总数已知时不会多请求一页
+	if calls.Load() != 3 {
+		t.Errorf("fetch called %d times, want 3", calls.Load())
+	}
+}
+
+func TestPageIteratorUnknownTotal(t *testing.T) {
+	fetch := func(ctx context.Context, page, pageSize int) ([]int, int, error) {
+		if page > 2 {
+			return nil, -1, nil
+		}
+		return make([]int, pageSize), -1, nil
+	}
+	var count int
+	err := ForEachPage(context.Background(), 5, fetch, func(items []int) error {
+		count += len(items)
+		return nil
+	})
+	if err != nil || count != 10 {
+		t.Errorf("ForEachPage() = %v, count %d, want 10", err, count)
+	}
+}
+
+func TestPageIteratorPrefetch(t *testing.T) {
+	started := make(chan int, 10)
+	fetch := func(ctx context.Context, page, pageSize int) ([]int, int, error) {
+		started <- page
+		return []int{page}, 3, nil
+	}
+	it := NewPageIterator(context.Background(), 1, fetch)
+	defer it.Close()
+	if !it.Next() {
+		t.Fatal(it.Err())
+	}
+	<-started
+	// 处理第 1 页时第 2 页已开始读取
+	select {
+	case page := <-started:
+		if page != 2 {
+			t.Errorf("prefetched page %d, want 2", page)
+		}
+	case <-time.After(time.Second):
+		t.Error("next page was not prefetched")
+	}
+}
+
+func TestPageIteratorError(t *testing.T) {
+	errFetch := errors.New("db down")
+	fetch := func(ctx context.Context, page, pageSize int) ([]int, int, error) {
+		if page == 2 {
+			return nil, 0, errFetch
+		}
+		return []int{1, 2}, 10, nil
+	}
+	var pages int
+	err := ForEachPage(context.Background(), 2, fetch, func(items []int) error {
+		pages++
+		return nil
+	})
+	if !errors.Is(err, errFetch) || pages != 1 {
+		t.Errorf("ForEachPage() = %v after %d pages", err, pages)
+	}
+
+	errStop := errors.New("stop")
+	err = ForEachPage(context.Background(), 2, fetch, func(items []int) error { return errStop })
+	if !errors.Is(err, errStop) {
+		t.Errorf("ForEachPage() = %v, want errStop", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it := NewPageIterator(ctx, 2, fetch)
+	if it.Next() {
+		t.Error("Next() on canceled context returned true")
+	}
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("Err() = %v, want context.Canceled", it.Err())
+	}
+}
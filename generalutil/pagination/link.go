@@ -0,0 +1,147 @@
+package pagination
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Links 分页链接，可直接作为响应 JSON 中的 links 对象，不存在的链接为空
+type Links struct {
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+type linkOptions struct {
+	pageParam     string
+	pageSizeParam string
+	cursorParam   string
+	cursor        bool
+}
+
+// LinkOption 分页链接选项
+type LinkOption func(o *linkOptions)
+
+// WithPageParam 设置页码参数名，默认 page
+func WithPageParam(name string) LinkOption {
+	return func(o *linkOptions) {
+		o.pageParam = name
+	}
+}
+
+// WithPageSizeParam 设置每页数量参数名，默认 page_size
+func WithPageSizeParam(name string) LinkOption {
+	return func(o *linkOptions) {
+		o.pageSizeParam = name
+	}
+}
+
+// WithCursorParam 设置游标参数名，默认 cursor
+func WithCursorParam(name string) LinkOption {
+	return func(o *linkOptions) {
+		o.cursorParam = name
+	}
+}
+
+// WithCursorStyle 强制使用游标分页生成链接，用于最后一页 NextCursor 为空且请求中没有游标参数的情况
+func WithCursorStyle() LinkOption {
+	return func(o *linkOptions) {
+		o.cursor = true
+	}
+}
+
+// Links 根据分页信息生成分页链接，base 通常为当前请求的 URL，其中的其他查询参数原样保留。
+// NextCursor 不为空或 base 中带有游标参数时按游标分页生成 first 和 next，
+// 否则按页码分页生成 first、prev、next、last，需在 Build 之后调用
+func (pb *Builder) Links(base string, opts ...LinkOption) (*Links, error) {
+	links, _, err := pb.links(base, opts)
+	return links, err
+}
+
+// links 生成分页链接，同时返回是否为游标分页
+func (pb *Builder) links(base string, opts []LinkOption) (*Links, bool, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, false, err
+	}
+	o := linkOptions{pageParam: "page", pageSizeParam: "page_size", cursorParam: "cursor"}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	query := u.Query()
+	if o.cursor || pb.NextCursor != "" || query.Has(o.cursorParam) {
+		return pb.cursorLinks(u, query, o), true, nil
+	}
+	return pb.pageLinks(u, query, o), false, nil
+}
+
+func (pb *Builder) pageLinks(u *url.URL, query url.Values, o linkOptions) *Links {
+	query.Set(o.pageSizeParam, strconv.Itoa(pb.PageSize))
+	link := func(page int) string {
+		if page < 1 {
+			return ""
+		}
+		query.Set(o.pageParam, strconv.Itoa(page))
+		return withQuery(u, query)
+	}
+	links := &Links{First: link(1), Prev: link(pb.Prev), Next: link(pb.Next)}
+	if pb.TotalPages > 0 {
+		links.Last = link(pb.TotalPages)
+	}
+	return links
+}
+
+// cursorLinks 游标分页只能向后翻页，没有 prev 和 last
+func (pb *Builder) cursorLinks(u *url.URL, query url.Values, o linkOptions) *Links {
+	query.Del(o.pageParam)
+	query.Set(o.pageSizeParam, strconv.Itoa(pb.PageSize))
+	query.Del(o.cursorParam)
+	links := &Links{First: withQuery(u, query)}
+	if pb.NextCursor != "" {
+		query.Set(o.cursorParam, pb.NextCursor)
+		links.Next = withQuery(u, query)
+	}
+	return links
+}
+
+func withQuery(u *url.URL, query url.Values) string {
+	link := *u
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// String 转换为 RFC 8288 Link 响应头格式，例如 <https://api/users?page=2>; rel="next"
+func (l *Links) String() string {
+	var parts []string
+	for _, link := range []struct{ rel, url string }{
+		{"first", l.First},
+		{"prev", l.Prev},
+		{"next", l.Next},
+		{"last", l.Last},
+	} {
+		if link.url != "" {
+			parts = append(parts, "<"+link.url+`>; rel="`+link.rel+`"`)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SetHeaders 设置 Link 和 X-Total-Count 响应头并返回分页链接，游标分页且总数未知（为 0）时不设置 X-Total-Count:
+//
+//	links, err := p.Pagination.SetHeaders(w.Header(), r.URL.String())
+func (pb *Builder) SetHeaders(h http.Header, base string, opts ...LinkOption) (*Links, error) {
+	links, cursor, err := pb.links(base, opts)
+	if err != nil {
+		return nil, err
+	}
+	if link := links.String(); link != "" {
+		h.Set("Link", link)
+	}
+	if !cursor || pb.Total > 0 {
+		h.Set("X-Total-Count", strconv.Itoa(pb.Total))
+	}
+	return links, nil
+}
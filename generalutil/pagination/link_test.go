@@ -0,0 +1,79 @@
+package pagination
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/lastares/claymore/protobuf/pagination"
+)
+
+func TestBuilderLinks(t *testing.T) {
+	builder := NewPaginationBuilder(10).WithPagination(&pagination.Pagination{Page: 2, PageSize: 3}).Build()
+	links, err := builder.Links("https://api.example.com/users?status=1&page=2")
+	if err != nil {
+		t.Fatalf("Links() err = %v", err)
+	}
+	want := Links{
+		First: "https://api.example.com/users?page=1&page_size=3&status=1",
+		Prev:  "https://api.example.com/users?page=1&page_size=3&status=1",
+		Next:  "https://api.example.com/users?page=3&page_size=3&status=1",
+		Last:  "https://api.example.com/users?page=4&page_size=3&status=1",
+	}
+	if *links != want {
+		t.Errorf("Links() = %+v, want %+v", *links, want)
+	}
+
+	// 第一页没有 prev，自定义参数名
+	builder = NewPaginationBuilder(10).WithPagination(&pagination.Pagination{Page: 1, PageSize: 5}).Build()
+	links, _ = builder.Links("/users", WithPageParam("p"), WithPageSizeParam("size"))
+	want = Links{First: "/users?p=1&size=5", Next: "/users?p=2&size=5", Last: "/users?p=2&size=5"}
+	if *links != want {
+		t.Errorf("Links() = %+v, want %+v", *links, want)
+	}
+}
+
+func TestBuilderCursorLinks(t *testing.T) {
+	builder := NewPaginationBuilder(0).WithPagination(&pagination.Pagination{PageSize: 20}).WithNextCursor("abc").Build()
+	links, err := builder.Links("/orders?cursor=xyz&status=paid")
+	if err != nil {
+		t.Fatalf("Links() err = %v", err)
+	}
+	want := Links{First: "/orders?page_size=20&status=paid", Next: "/orders?cursor=abc&page_size=20&status=paid"}
+	if *links != want {
+		t.Errorf("Links() = %+v, want %+v", *links, want)
+	}
+
+	// 最后一页请求中带游标，没有 next
+	builder = NewPaginationBuilder(0).WithPagination(&pagination.Pagination{PageSize: 20}).Build()
+	links, _ = builder.Links("/orders?cursor=xyz")
+	if links.First != "/orders?page_size=20" || links.Next != "" || links.Last != "" {
+		t.Errorf("Links() = %+v", *links)
+	}
+}
+
+func TestBuilderSetHeaders(t *testing.T) {
+	builder := NewPaginationBuilder(10).WithPagination(&pagination.Pagination{Page: 1, PageSize: 5}).Build()
+	h := http.Header{}
+	if _, err := builder.SetHeaders(h, "/users"); err != nil {
+		t.Fatalf("SetHeaders() err = %v", err)
+	}
+	wantLink := `</users?page=1&page_size=5>; rel="first", </users?page=2&page_size=5>; rel="next", </users?page=2&page_size=5>; rel="last"`
+	if got := h.Get("Link"); got != wantLink {
+		t.Errorf("Link = %s, want %s", got, wantLink)
+	}
+	if got := h.Get("X-Total-Count"); got != "10" {
+		t.Errorf("X-Total-Count = %s, want 10", got)
+	}
+
+	// 游标分页总数未知时不设置 X-Total-Count
+	h = http.Header{}
+	builder = NewPaginationBuilder(0).WithPagination(&pagination.Pagination{PageSize: 5}).WithNextCursor("abc").Build()
+	_, _ = builder.SetHeaders(h, "/users")
+	if _, ok := h["X-Total-Count"]; ok {
+		t.Errorf("X-Total-Count set for cursor pagination without total")
+	}
+
+	if _, err := builder.SetHeaders(http.Header{}, "://bad"); err == nil {
+		t.Errorf("SetHeaders() with invalid base url err = nil")
+	}
+}
@@ -1,8 +1,6 @@
 package pagination
 
 import (
-	"math"
-
 	"github.com/lastares/claymore/protobuf/pagination"
 )
 
@@ -11,6 +9,11 @@ type Paginator[T any] struct {
 	Pagination *Builder
 }
 
+// Err 分页参数不符合策略时返回 *RangeError
+func (p *Paginator[T]) Err() error {
+	return p.Pagination.Err()
+}
+
 type Builder struct {
 	Page       int
 	PageSize   int
@@ -19,18 +22,42 @@ type Builder struct {
 	Next       int
 	Prev       int
 	HasMore    bool
+	NextCursor string
+	// OutOfRange 页码超过总页数，此时列表为空，Prev 指向最后一页，Next 为 0
+	OutOfRange bool
+
+	policy *Policy
+	err    error
+}
+
+// PaginatorOption NewPaginator、Paginate 的选项
+type PaginatorOption func(pb *Builder)
+
+// WithPaginatorPolicy 指定分页参数策略，Reject 模式下参数超出范围时 Paginator.Err 返回 *RangeError
+func WithPaginatorPolicy(policy Policy) PaginatorOption {
+	return func(pb *Builder) {
+		pb.WithPolicy(policy)
+	}
 }
 
-func NewPaginator[T any](total int, list T, p *pagination.Pagination) *Paginator[T] {
+func NewPaginator[T any](total int, list T, p *pagination.Pagination, opts ...PaginatorOption) *Paginator[T] {
 	return &Paginator[T]{
 		List: list,
-		Pagination: NewPaginationBuilder(total).WithPagination(&pagination.Pagination{
+		Pagination: newBuilder(total, opts).WithPagination(&pagination.Pagination{
 			Page:     p.Page,
 			PageSize: p.PageSize,
 		}).Build(),
 	}
 }
 
+func newBuilder(total int, opts []PaginatorOption) *Builder {
+	pb := NewPaginationBuilder(total)
+	for _, opt := range opts {
+		opt(pb)
+	}
+	return pb
+}
+
 // NewPaginationBuilder 创建一个新的空的 PaginationBuilder 实例
 func NewPaginationBuilder(total int) *Builder {
 	return &Builder{
@@ -38,52 +65,117 @@ func NewPaginationBuilder(total int) *Builder {
 	}
 }
 
-func (pb *Builder) setPage(page int) {
-	if page == 0 {
-		page = 1
-	}
-	pb.Page = page
+// WithPolicy 设置分页参数策略，未设置时使用 DefaultPolicy
+func (pb *Builder) WithPolicy(policy Policy) *Builder {
+	pb.policy = &policy
+	return pb
 }
 
-func (pb *Builder) setPageSize(pageSize int) {
-	if pageSize == 0 {
-		pageSize = 10
+func (pb *Builder) getPolicy() Policy {
+	if pb.policy != nil {
+		return *pb.policy
 	}
-	pb.PageSize = pageSize
+	return DefaultPolicy
 }
 
 func (pb *Builder) WithPagination(p *pagination.Pagination) *Builder {
-	pb.setPage(int(p.Page))
-	pb.setPageSize(int(p.PageSize))
+	pb.Page = int(p.GetPage())
+	pb.PageSize = int(p.GetPageSize())
 	return pb
 }
 
+// normalize 按策略规范化页码和每页数量，Reject 模式下参数超出范围时记录错误，
+// 并按 Clamp 修正参数，保证后续计算不会出现除零或负数偏移
+func (pb *Builder) normalize() {
+	policy := pb.getPolicy()
+	req := &pagination.Pagination{Page: int32(pb.Page), PageSize: int32(pb.PageSize)}
+	page, pageSize, err := policy.Normalize(req)
+	if err != nil {
+		pb.err = err
+		policy.OnOutOfRange = Clamp
+		page, pageSize, _ = policy.Normalize(req)
+	}
+	pb.Page, pb.PageSize = page, pageSize
+}
+
 func (pb *Builder) setTotalPages() {
-	pb.TotalPages = int(math.Ceil(float64(pb.Total) / float64(pb.PageSize)))
+	pb.TotalPages = (max(pb.Total, 0) + pb.PageSize - 1) / pb.PageSize
+}
+
+// setOutOfRange 页码超过总页数时标记越界，总数为 0 时第 1 页不算越界
+func (pb *Builder) setOutOfRange() {
+	pb.OutOfRange = pb.Page > max(pb.TotalPages, 1)
+	if pb.OutOfRange && pb.err == nil && pb.getPolicy().OnOutOfRange == Reject {
+		pb.err = &RangeError{Field: "page", Value: pb.Page, Min: 1, Max: max(pb.TotalPages, 1)}
+	}
 }
 
 func (pb *Builder) setPrev() {
-	if pb.Page > 1 {
-		pb.Prev = max(1, pb.Page-1)
+	switch {
+	case pb.OutOfRange:
+		pb.Prev = pb.TotalPages
+	case pb.Page > 1:
+		pb.Prev = pb.Page - 1
 	}
 }
 
 func (pb *Builder) setNext() {
-	if pb.TotalPages > 1 && pb.Page < pb.TotalPages {
-		pb.Next = min(pb.TotalPages, pb.Page+1)
+	if pb.Page < pb.TotalPages {
+		pb.Next = pb.Page + 1
 	}
 }
 
 // HasMore
 func (pb *Builder) setHasMore() {
-	pb.HasMore = pb.Page < pb.TotalPages
+	pb.HasMore = pb.Page < pb.TotalPages || pb.NextCursor != ""
+}
+
+// WithNextCursor 游标分页时设置下一页的游标，游标不为空时 HasMore 为 true
+func (pb *Builder) WithNextCursor(cursor string) *Builder {
+	pb.NextCursor = cursor
+	return pb
 }
 
 // Build 构建最终的 PaginationBuilder 实例
 func (pb *Builder) Build() *Builder {
+	pb.normalize()
 	pb.setTotalPages()
+	pb.setOutOfRange()
 	pb.setPrev()
 	pb.setNext()
 	pb.setHasMore()
 	return pb
 }
+
+// Err 分页参数不符合策略时返回 *RangeError，只有 Reject 模式会产生错误，需在 Build 之后调用
+func (pb *Builder) Err() error {
+	return pb.err
+}
+
+// Reply 转换为 proto 分页响应，供 gRPC 接口和网关 JSON 使用
+func (pb *Builder) Reply() *pagination.PaginationReply {
+	return &pagination.PaginationReply{
+		Page:       int32(pb.Page),
+		PageSize:   int32(pb.PageSize),
+		Total:      int64(pb.Total),
+		TotalPages: int32(pb.TotalPages),
+		Next:       int32(pb.Next),
+		Prev:       int32(pb.Prev),
+		HasMore:    pb.HasMore,
+		NextCursor: pb.NextCursor,
+	}
+}
+
+// FromReply 由 proto 分页响应还原 Builder，例如调用方从 gRPC 响应中读取分页信息
+func FromReply(reply *pagination.PaginationReply) *Builder {
+	return &Builder{
+		Page:       int(reply.GetPage()),
+		PageSize:   int(reply.GetPageSize()),
+		Total:      int(reply.GetTotal()),
+		TotalPages: int(reply.GetTotalPages()),
+		Next:       int(reply.GetNext()),
+		Prev:       int(reply.GetPrev()),
+		HasMore:    reply.GetHasMore(),
+		NextCursor: reply.GetNextCursor(),
+	}
+}
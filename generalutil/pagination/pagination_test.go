@@ -2,8 +2,12 @@ package pagination
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
 	"github.com/lastares/claymore/protobuf/pagination"
 )
 
@@ -189,3 +193,28 @@ func TestNewPaginationBuilder(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilderReply(t *testing.T) {
+	builder := NewPaginationBuilder(10).WithPagination(&pagination.Pagination{Page: 2, PageSize: 3}).Build()
+	reply := builder.Reply()
+	want := &pagination.PaginationReply{Page: 2, PageSize: 3, Total: 10, TotalPages: 4, Next: 3, Prev: 1, HasMore: true}
+	if !proto.Equal(reply, want) {
+		t.Errorf("Reply() = %v, want %v", reply, want)
+	}
+	if got := FromReply(reply); *got != *builder {
+		t.Errorf("FromReply() = %+v, want %+v", got, builder)
+	}
+
+	// 游标分页：没有总数，由游标决定是否还有更多数据
+	reply = NewPaginationBuilder(0).WithPagination(&pagination.Pagination{PageSize: 20}).WithNextCursor("eyJpZCI6MTAwfQ").Build().Reply()
+	if !reply.HasMore || reply.NextCursor != "eyJpZCI6MTAwfQ" {
+		t.Errorf("Reply() with cursor = %v, want has_more and next_cursor", reply)
+	}
+	data, err := protojson.Marshal(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"nextCursor":"eyJpZCI6MTAwfQ"`) {
+		t.Errorf("protojson = %s, want nextCursor", data)
+	}
+}
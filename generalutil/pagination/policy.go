@@ -0,0 +1,113 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lastares/claymore/protobuf/pagination"
+)
+
+// ErrOutOfRange 分页参数超出允许范围，可通过 errors.Is 判断，具体信息见 *RangeError
+var ErrOutOfRange = errors.New("pagination: out of range")
+
+// RangeError 分页参数超出范围
+type RangeError struct {
+	// Field 超出范围的参数：page 或 page_size
+	Field string
+	Value int
+	Min   int
+	// Max 允许的最大值，0 表示不限制
+	Max int
+}
+
+func (e *RangeError) Error() string {
+	if e.Max > 0 {
+		return fmt.Sprintf("pagination: %s %d out of range [%d, %d]", e.Field, e.Value, e.Min, e.Max)
+	}
+	return fmt.Sprintf("pagination: %s %d must be at least %d", e.Field, e.Value, e.Min)
+}
+
+func (e *RangeError) Is(target error) bool {
+	return target == ErrOutOfRange
+}
+
+// OutOfRangeMode 分页参数超出范围时的处理方式
+type OutOfRangeMode int
+
+const (
+	// Clamp 修正到最近的合法值
+	Clamp OutOfRangeMode = iota
+	// Reject 返回 *RangeError
+	Reject
+)
+
+// Policy 分页参数策略
+type Policy struct {
+	// DefaultPageSize 未传 page_size 时使用的每页数量
+	DefaultPageSize int
+	// MaxPageSize 每页数量上限，0 表示不限制
+	MaxPageSize int
+	// MaxPage 页码上限，用于限制深分页，0 表示不限制
+	MaxPage int
+	// OnOutOfRange 超出范围时的处理方式
+	OnOutOfRange OutOfRangeMode
+}
+
+// DefaultPolicy 默认分页策略：每页 10 条，不限制每页数量和页码，负数参数修正为默认值。
+// 需要限制每页数量时通过 Builder.WithPolicy 或 WithPaginatorPolicy 指定策略
+var DefaultPolicy = Policy{DefaultPageSize: 10, OnOutOfRange: Clamp}
+
+// Normalize 按策略校验分页参数，返回规范化后的页码和每页数量。
+// 未传（为 0）的参数使用默认值；负数和超过上限的参数按 OnOutOfRange 修正或返回 *RangeError
+func (p Policy) Normalize(req *pagination.Pagination) (page, pageSize int, err error) {
+	if page, err = p.normalizePage(int(req.GetPage())); err != nil {
+		return 0, 0, err
+	}
+	if pageSize, err = p.normalizePageSize(int(req.GetPageSize())); err != nil {
+		return 0, 0, err
+	}
+	return page, pageSize, nil
+}
+
+func (p Policy) normalizePage(page int) (int, error) {
+	switch {
+	case page == 0:
+		return 1, nil
+	case page < 0 || (p.MaxPage > 0 && page > p.MaxPage):
+		if p.OnOutOfRange == Reject {
+			return 0, &RangeError{Field: "page", Value: page, Min: 1, Max: p.MaxPage}
+		}
+		return max(1, min(page, p.maxPage())), nil
+	}
+	return page, nil
+}
+
+func (p Policy) normalizePageSize(pageSize int) (int, error) {
+	switch {
+	case pageSize == 0:
+		return p.defaultPageSize(), nil
+	case pageSize < 0 || (p.MaxPageSize > 0 && pageSize > p.MaxPageSize):
+		if p.OnOutOfRange == Reject {
+			return 0, &RangeError{Field: "page_size", Value: pageSize, Min: 1, Max: p.MaxPageSize}
+		}
+		if pageSize < 0 {
+			return p.defaultPageSize(), nil
+		}
+		return p.MaxPageSize, nil
+	}
+	return pageSize, nil
+}
+
+func (p Policy) defaultPageSize() int {
+	if p.DefaultPageSize > 0 {
+		return p.DefaultPageSize
+	}
+	return DefaultPolicy.DefaultPageSize
+}
+
+func (p Policy) maxPage() int {
+	if p.MaxPage > 0 {
+		return p.MaxPage
+	}
+	return int(^uint(0) >> 1)
+}
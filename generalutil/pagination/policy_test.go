@@ -0,0 +1,122 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lastares/claymore/protobuf/pagination"
+)
+
+func TestPolicyNormalize(t *testing.T) {
+	clamp := Policy{DefaultPageSize: 20, MaxPageSize: 100, MaxPage: 50}
+	reject := clamp
+	reject.OnOutOfRange = Reject
+	tests := []struct {
+		name         string
+		policy       Policy
+		page, size   int32
+		wantPage     int
+		wantSize     int
+		wantErrField string
+	}{
+		{"defaults", clamp, 0, 0, 1, 20, ""},
+		{"in range", clamp, 3, 50, 3, 50, ""},
+		{"clamp negative", clamp, -2, -5, 1, 20, ""},
+		{"clamp max", clamp, 80, 1000000, 50, 100, ""},
+		{"reject negative page", reject, -1, 10, 0, 0, "page"},
+		{"reject max page", reject, 51, 10, 0, 0, "page"},
+		{"reject page size", reject, 1, 101, 0, 0, "page_size"},
+		{"unlimited", Policy{}, 1000, 5000, 1000, 5000, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, size, err := tt.policy.Normalize(&pagination.Pagination{Page: tt.page, PageSize: tt.size})
+			if tt.wantErrField != "" {
+				var rangeErr *RangeError
+				if !errors.As(err, &rangeErr) || rangeErr.Field != tt.wantErrField {
+					t.Fatalf("Normalize() err = %v, want RangeError on %s", err, tt.wantErrField)
+				}
+				if !errors.Is(err, ErrOutOfRange) {
+					t.Errorf("Normalize() err = %v, want ErrOutOfRange", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize() err = %v", err)
+			}
+			if page != tt.wantPage || size != tt.wantSize {
+				t.Errorf("Normalize() = (%d, %d), want (%d, %d)", page, size, tt.wantPage, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestBuilderPolicy(t *testing.T) {
+	// 默认策略不限制每页数量
+	builder := NewPaginationBuilder(100).WithPagination(&pagination.Pagination{Page: -3, PageSize: 1000000}).Build()
+	if builder.Page != 1 || builder.PageSize != 1000000 || builder.Err() != nil {
+		t.Errorf("Build() = %+v, err = %v", builder, builder.Err())
+	}
+
+	builder = NewPaginationBuilder(100).
+		WithPolicy(Policy{MaxPageSize: 50, OnOutOfRange: Reject}).
+		WithPagination(&pagination.Pagination{Page: 1, PageSize: 51}).
+		Build()
+	var rangeErr *RangeError
+	if !errors.As(builder.Err(), &rangeErr) || rangeErr.Field != "page_size" || rangeErr.Max != 50 {
+		t.Errorf("Build() err = %v, want page_size RangeError", builder.Err())
+	}
+	// 出错时仍按修正后的参数计算，避免除零
+	if builder.PageSize != 50 || builder.TotalPages != 2 {
+		t.Errorf("Build() = %+v", builder)
+	}
+}
+
+func TestBuilderOutOfRange(t *testing.T) {
+	builder := NewPaginationBuilder(10).WithPagination(&pagination.Pagination{Page: 7, PageSize: 3}).Build()
+	if !builder.OutOfRange || builder.Prev != 4 || builder.Next != 0 || builder.HasMore {
+		t.Errorf("Build() = %+v, want out of range with Prev = 4", builder)
+	}
+	if builder.Err() != nil {
+		t.Errorf("Build() err = %v, want nil with Clamp", builder.Err())
+	}
+
+	// 没有数据时第 1 页不算越界
+	builder = NewPaginationBuilder(0).WithPagination(&pagination.Pagination{Page: 1}).Build()
+	if builder.OutOfRange || builder.Prev != 0 || builder.Next != 0 {
+		t.Errorf("Build() = %+v, want first page in range", builder)
+	}
+
+	p := NewPaginator(10, []int{}, &pagination.Pagination{Page: 5, PageSize: 5})
+	if !p.Pagination.OutOfRange || p.Err() != nil {
+		t.Errorf("NewPaginator() = %+v, err = %v", p.Pagination, p.Err())
+	}
+
+	builder = NewPaginationBuilder(10).
+		WithPolicy(Policy{OnOutOfRange: Reject}).
+		WithPagination(&pagination.Pagination{Page: 5, PageSize: 5}).
+		Build()
+	var rangeErr *RangeError
+	if !errors.As(builder.Err(), &rangeErr) || rangeErr.Field != "page" || rangeErr.Max != 2 {
+		t.Errorf("Build() err = %v, want page RangeError with Max = 2", builder.Err())
+	}
+}
+
+func TestPaginatorPolicy(t *testing.T) {
+	reject := WithPaginatorPolicy(Policy{MaxPageSize: 50, OnOutOfRange: Reject})
+	p := NewPaginator(100, []int{}, &pagination.Pagination{Page: 1, PageSize: 100}, reject)
+	if !errors.Is(p.Err(), ErrOutOfRange) || p.Pagination.PageSize != 50 {
+		t.Errorf("NewPaginator() = %+v, err = %v, want ErrOutOfRange", p.Pagination, p.Err())
+	}
+
+	items := make([]int, 100)
+	sp := Paginate(items, &pagination.Pagination{Page: 1, PageSize: 100}, reject)
+	if !errors.Is(sp.Err(), ErrOutOfRange) || len(sp.List) != 50 {
+		t.Errorf("Paginate() len = %d, err = %v, want 50 items and ErrOutOfRange", len(sp.List), sp.Err())
+	}
+
+	sp = Paginate(items, &pagination.Pagination{Page: 1, PageSize: 100}, WithPaginatorPolicy(Policy{MaxPageSize: 30}))
+	if sp.Err() != nil || len(sp.List) != 30 {
+		t.Errorf("Paginate() len = %d, err = %v, want 30 items", len(sp.List), sp.Err())
+	}
+}
@@ -0,0 +1,26 @@
+package pagination
+
+import (
+	"github.com/lastares/claymore/protobuf/pagination"
+)
+
+// Paginate 对内存中的切片分页，返回当前页的子切片及分页信息，适用于缓存数据等已全部加载的列表。
+// 返回的子切片与 items 共享底层数组，但容量截断到当前页，追加元素不会覆盖 items 中后面的数据
+func Paginate[T any](items []T, p *pagination.Pagination, opts ...PaginatorOption) *Paginator[[]T] {
+	builder := newBuilder(len(items), opts).WithPagination(p).Build()
+	start, end := builder.bounds(len(items))
+	return &Paginator[[]T]{
+		List:       items[start:end:end],
+		Pagination: builder,
+	}
+}
+
+// bounds 返回当前页在长度为 n 的列表中的起止下标，页码超出范围时返回空区间
+func (pb *Builder) bounds(n int) (start, end int) {
+	if pb.Page < 1 || pb.PageSize < 1 {
+		return 0, 0
+	}
+	start = min((pb.Page-1)*pb.PageSize, n)
+	end = min(start+pb.PageSize, n)
+	return start, end
+}
@@ -0,0 +1,196 @@
+package pagination
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lastares/claymore/protobuf/pagination"
+)
+
+// ErrInvalidSort 排序参数格式错误或字段不在白名单中
+var ErrInvalidSort = errors.New("pagination: invalid sort")
+
+var sortFieldPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// ParseSort 解析排序字符串，多个字段以逗号分隔，字段前加 - 表示降序，
+// 可以用 :nulls_first 或 :nulls_last 指定空值位置，例如 "-created_at:nulls_last,name"
+func ParseSort(s string) ([]*pagination.Sort, error) {
+	var sorts []*pagination.Sort
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		sort := &pagination.Sort{Direction: pagination.Sort_DIRECTION_ASC}
+		switch item[0] {
+		case '-':
+			sort.Direction = pagination.Sort_DIRECTION_DESC
+			item = item[1:]
+		case '+':
+			item = item[1:]
+		}
+		field, nulls, _ := strings.Cut(item, ":")
+		switch strings.ToLower(nulls) {
+		case "":
+		case "nulls_first":
+			sort.Nulls = pagination.Sort_NULLS_FIRST
+		case "nulls_last":
+			sort.Nulls = pagination.Sort_NULLS_LAST
+		default:
+			return nil, fmt.Errorf("%w: unknown nulls option %q", ErrInvalidSort, nulls)
+		}
+		if !sortFieldPattern.MatchString(field) {
+			return nil, fmt.Errorf("%w: invalid field %q", ErrInvalidSort, field)
+		}
+		sort.Field = field
+		sorts = append(sorts, sort)
+	}
+	return sorts, nil
+}
+
+// FormatSort 将排序规则格式化为 ParseSort 可以解析的字符串
+func FormatSort(sorts []*pagination.Sort) string {
+	items := make([]string, 0, len(sorts))
+	for _, sort := range sorts {
+		item := sort.GetField()
+		if sort.GetDirection() == pagination.Sort_DIRECTION_DESC {
+			item = "-" + item
+		}
+		switch sort.GetNulls() {
+		case pagination.Sort_NULLS_FIRST:
+			item += ":nulls_first"
+		case pagination.Sort_NULLS_LAST:
+			item += ":nulls_last"
+		}
+		items = append(items, item)
+	}
+	return strings.Join(items, ",")
+}
+
+// SortFields 接口允许排序的字段白名单，key 为接口中的字段名，value 为数据库列名，为空时与字段名相同
+type SortFields map[string]string
+
+// Validate 检查排序字段是否都在白名单中
+func (f SortFields) Validate(sorts []*pagination.Sort) error {
+	for _, sort := range sorts {
+		if _, ok := f[sort.GetField()]; !ok {
+			return fmt.Errorf("%w: field %q is not sortable", ErrInvalidSort, sort.GetField())
+		}
+	}
+	return nil
+}
+
+// Column 返回字段对应的数据库列名
+func (f SortFields) Column(field string) (string, bool) {
+	column, ok := f[field]
+	if !ok {
+		return "", false
+	}
+	if column == "" {
+		column = field
+	}
+	return column, true
+}
+
+// SortKeys 内存排序时各字段的取值函数，同时作为排序字段白名单。
+// 取值支持整数、浮点数、字符串、布尔值、time.Time 以及它们的指针，nil 视为空值
+type SortKeys[T any] map[string]func(item T) any
+
+// SortSlice 按排序规则对切片做稳定排序，与 dbutil.OrderBy 使用同一份排序规则
+func SortSlice[T any](items []T, sorts []*pagination.Sort, keys SortKeys[T]) error {
+	for _, sort := range sorts {
+		if _, ok := keys[sort.GetField()]; !ok {
+			return fmt.Errorf("%w: field %q is not sortable", ErrInvalidSort, sort.GetField())
+		}
+	}
+	slices.SortStableFunc(items, func(a, b T) int {
+		for _, sort := range sorts {
+			key := keys[sort.GetField()]
+			if c := compareSortValues(key(a), key(b), sort); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return nil
+}
+
+// compareSortValues 按排序方向和空值位置比较两个值
+func compareSortValues(a, b any, sort *pagination.Sort) int {
+	a, b = deref(a), deref(b)
+	desc := sort.GetDirection() == pagination.Sort_DIRECTION_DESC
+	if a == nil || b == nil {
+		if a == nil && b == nil {
+			return 0
+		}
+		// 空值在前返回 -1
+		first := -1
+		switch sort.GetNulls() {
+		case pagination.Sort_NULLS_LAST:
+			first = 1
+		case pagination.Sort_NULLS_UNSPECIFIED:
+			// 空值视为最小值，降序时排在最后
+			if desc {
+				first = 1
+			}
+		}
+		if a == nil {
+			return first
+		}
+		return -first
+	}
+	c := compareValues(a, b)
+	if desc {
+		return -c
+	}
+	return c
+}
+
+func deref(v any) any {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	return rv.Interface()
+}
+
+func compareValues(a, b any) int {
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Compare(tb)
+		}
+	}
+	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case ra.CanInt() && rb.CanInt():
+		return cmp.Compare(ra.Int(), rb.Int())
+	case ra.CanUint() && rb.CanUint():
+		return cmp.Compare(ra.Uint(), rb.Uint())
+	case ra.CanFloat() && rb.CanFloat():
+		return cmp.Compare(ra.Float(), rb.Float())
+	case ra.Kind() == reflect.String && rb.Kind() == reflect.String:
+		return cmp.Compare(ra.String(), rb.String())
+	case ra.Kind() == reflect.Bool && rb.Kind() == reflect.Bool:
+		switch {
+		case ra.Bool() == rb.Bool():
+			return 0
+		case rb.Bool():
+			return -1
+		}
+		return 1
+	}
+	// 不支持的类型按字符串比较
+	return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
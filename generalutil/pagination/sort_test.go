@@ -0,0 +1,116 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lastares/claymore/protobuf/pagination"
+)
+
+func TestParseSort(t *testing.T) {
+	sorts, err := ParseSort(" -created_at:nulls_last, name ,+id:NULLS_FIRST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*pagination.Sort{
+		{Field: "created_at", Direction: pagination.Sort_DIRECTION_DESC, Nulls: pagination.Sort_NULLS_LAST},
+		{Field: "name", Direction: pagination.Sort_DIRECTION_ASC},
+		{Field: "id", Direction: pagination.Sort_DIRECTION_ASC, Nulls: pagination.Sort_NULLS_FIRST},
+	}
+	if len(sorts) != len(want) {
+		t.Fatalf("ParseSort() got %d sorts, want %d", len(sorts), len(want))
+	}
+	for i := range want {
+		if sorts[i].Field != want[i].Field || sorts[i].Direction != want[i].Direction || sorts[i].Nulls != want[i].Nulls {
+			t.Errorf("ParseSort()[%d] = %v, want %v", i, sorts[i], want[i])
+		}
+	}
+	if got := FormatSort(sorts); got != "-created_at:nulls_last,name,id:nulls_first" {
+		t.Errorf("FormatSort() = %q", got)
+	}
+
+	for _, s := range []string{"name;drop table", "-", "name:nulls_middle", "1abc"} {
+		if _, err = ParseSort(s); !errors.Is(err, ErrInvalidSort) {
+			t.Errorf("ParseSort(%q) error = %v, want ErrInvalidSort", s, err)
+		}
+	}
+	if sorts, err = ParseSort(""); err != nil || len(sorts) != 0 {
+		t.Errorf("ParseSort(\"\") = %v, %v", sorts, err)
+	}
+}
+
+func TestSortFields(t *testing.T) {
+	fields := SortFields{"name": "", "created": "created_at"}
+	sorts, _ := ParseSort("name,-created")
+	if err := fields.Validate(sorts); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if column, _ := fields.Column("created"); column != "created_at" {
+		t.Errorf("Column() = %q, want created_at", column)
+	}
+	if column, _ := fields.Column("name"); column != "name" {
+		t.Errorf("Column() = %q, want name", column)
+	}
+	sorts, _ = ParseSort("password")
+	if err := fields.Validate(sorts); !errors.Is(err, ErrInvalidSort) {
+		t.Errorf("Validate() error = %v, want ErrInvalidSort", err)
+	}
+}
+
+func TestSortSlice(t *testing.T) {
+	type user struct {
+		Name      string
+		Age       *int
+		CreatedAt time.Time
+	}
+	age := func(v int) *int { return &v }
+	now := time.Now()
+	users := []user{
+		{Name: "b", Age: age(30), CreatedAt: now},
+		{Name: "a", Age: nil, CreatedAt: now.Add(time.Hour)},
+		{Name: "c", Age: age(20), CreatedAt: now.Add(-time.Hour)},
+		{Name: "a", Age: age(40), CreatedAt: now},
+	}
+	keys := SortKeys[user]{
+		"name":       func(u user) any { return u.Name },
+		"age":        func(u user) any { return u.Age },
+		"created_at": func(u user) any { return u.CreatedAt },
+	}
+	names := func(s string) string {
+		sorts, err := ParseSort(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list := append([]user(nil), users...)
+		if err = SortSlice(list, sorts, keys); err != nil {
+			t.Fatal(err)
+		}
+		var result string
+		for _, u := range list {
+			result += u.Name
+		}
+		return result
+	}
+	tests := map[string]string{
+		"name":                 "aabc",
+		"-name":                "cbaa",
+		"age":                  "acba",
+		"-age":                 "abca",
+		"age:nulls_last":       "cbaa",
+		"-age:nulls_first":     "aabc",
+		"-created_at,name":     "aabc",
+		"name,-age":            "aabc",
+		"name,age:nulls_first": "aabc",
+	}
+	for sort, want := range tests {
+		if got := names(sort); got != want {
+			t.Errorf("SortSlice(%q) = %s, want %s", sort, got, want)
+		}
+	}
+
+	sorts, _ := ParseSort("email")
+	if err := SortSlice(users, sorts, keys); !errors.Is(err, ErrInvalidSort) {
+		t.Errorf("SortSlice() error = %v, want ErrInvalidSort", err)
+	}
+}
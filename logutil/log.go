@@ -0,0 +1,73 @@
+package logutil
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+// ParseLevel 解析日志级别，支持 debug、info、warn/warning、error，不区分大小写
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("logutil: unknown log level %q", s)
+}
+
+// New 按日志配置创建 slog.Logger，未配置日志级别时使用 app 运行环境的默认级别（见 conf.EnvDefaults）。
+// 输出到文件时返回的 cleanup 用于关闭文件，其他情况下为空操作
+func New(c *conf.Log, app *conf.App) (logger *slog.Logger, cleanup func(), err error) {
+	level := app.Defaults().LogLevel
+	if c.GetLevel() != "" {
+		if level, err = ParseLevel(c.GetLevel()); err != nil {
+			return nil, nil, err
+		}
+	}
+	w, cleanup, err := output(c.GetOutput())
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := &slog.HandlerOptions{Level: level, AddSource: c.GetAddSource()}
+	var handler slog.Handler
+	if c.GetFormat() == conf.Log_FORMAT_TEXT {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+	attrs := make([]slog.Attr, 0, 3)
+	if app.GetName() != "" {
+		attrs = append(attrs, slog.String("service", app.GetName()))
+	}
+	if app.GetVersion() != "" {
+		attrs = append(attrs, slog.String("version", app.GetVersion()))
+	}
+	if app.GetEnv() != "" {
+		attrs = append(attrs, slog.String("env", string(app.Environment())))
+	}
+	return slog.New(handler.WithAttrs(attrs)), cleanup, nil
+}
+
+func output(target string) (io.Writer, func(), error) {
+	switch target {
+	case "", "stdout":
+		return os.Stdout, func() {}, nil
+	case "stderr":
+		return os.Stderr, func() {}, nil
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("logutil: open log file: %w", err)
+	}
+	return f, func() { _ = f.Close() }, nil
+}
@@ -0,0 +1,61 @@
+package logutil
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("WARNING")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	app := &conf.App{Name: "order", Env: "production"}
+
+	logger, cleanup, err := New(&conf.Log{Output: path, Format: conf.Log_FORMAT_JSON}, app)
+	require.NoError(t, err)
+	// 生产环境默认 info 级别
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelDebug))
+	logger.Info("hello", "k", "v")
+	cleanup()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(data, &record))
+	assert.Equal(t, "hello", record["msg"])
+	assert.Equal(t, "order", record["service"])
+	assert.Equal(t, "prod", record["env"])
+	assert.Equal(t, "v", record["k"])
+
+	logger, cleanup, err = New(&conf.Log{Level: "debug", Format: conf.Log_FORMAT_TEXT, Output: "stderr"}, app)
+	require.NoError(t, err)
+	defer cleanup()
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelDebug))
+
+	logger, _, err = New(nil, &conf.App{Env: "dev"})
+	require.NoError(t, err)
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelDebug))
+
+	_, _, err = New(&conf.Log{Level: "loud"}, app)
+	assert.ErrorContains(t, err, "loud")
+
+	_, _, err = New(&conf.Log{Output: filepath.Join(t.TempDir(), "missing", "app.log")}, app)
+	assert.True(t, strings.Contains(err.Error(), "open log file"))
+}
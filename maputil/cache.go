@@ -0,0 +1,510 @@
+package maputil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EvictionPolicy 缓存满时的淘汰策略
+type EvictionPolicy int
+
+const (
+	// LRU 淘汰最久未访问的条目
+	LRU EvictionPolicy = iota
+	// LFU 淘汰访问次数最少的条目，次数相同时淘汰最久未访问的
+	LFU
+)
+
+// EvictReason 条目被移出缓存的原因
+type EvictReason int
+
+const (
+	// EvictCapacity 超出容量被淘汰
+	EvictCapacity EvictReason = iota
+	// EvictExpired 过期被清理
+	EvictExpired
+	// EvictDeleted 调用 Delete 或 Clear 删除
+	EvictDeleted
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictExpired:
+		return "expired"
+	case EvictDeleted:
+		return "deleted"
+	}
+	return fmt.Sprintf("EvictReason(%d)", int(r))
+}
+
+type cacheOptions[K comparable, V any] struct {
+	capacity        int
+	policy          EvictionPolicy
+	ttl             time.Duration
+	cleanupInterval time.Duration
+	onEvict         func(key K, value V, reason EvictReason)
+	loadTimeout     time.Duration
+}
+
+// CacheOption 缓存选项，键值类型与缓存一致，类型不匹配时编译报错。
+// 除 WithEvictCallback 外需要显式指定类型参数:
+//
+//	maputil.NewCache(maputil.WithCapacity[string, *User](1000), maputil.WithTTL[string, *User](time.Minute))
+type CacheOption[K comparable, V any] func(o *cacheOptions[K, V])
+
+// WithCapacity 设置缓存容量，超出时按淘汰策略淘汰，0 表示不限制
+func WithCapacity[K comparable, V any](capacity int) CacheOption[K, V] {
+	return func(o *cacheOptions[K, V]) {
+		o.capacity = capacity
+	}
+}
+
+// WithEvictionPolicy 设置淘汰策略，默认 LRU
+func WithEvictionPolicy[K comparable, V any](policy EvictionPolicy) CacheOption[K, V] {
+	return func(o *cacheOptions[K, V]) {
+		o.policy = policy
+	}
+}
+
+// WithTTL 设置 Set 和 GetOrLoad 写入条目的默认过期时间，0 表示不过期
+func WithTTL[K comparable, V any](ttl time.Duration) CacheOption[K, V] {
+	return func(o *cacheOptions[K, V]) {
+		o.ttl = ttl
+	}
+}
+
+// WithCleanupInterval 启动后台协程定期清理过期条目，不设置时只在访问时清理，需调用 Close 停止
+func WithCleanupInterval[K comparable, V any](interval time.Duration) CacheOption[K, V] {
+	return func(o *cacheOptions[K, V]) {
+		o.cleanupInterval = interval
+	}
+}
+
+// WithEvictCallback 设置条目移出缓存时的回调，回调在锁外执行，可以再访问缓存
+func WithEvictCallback[K comparable, V any](fn func(key K, value V, reason EvictReason)) CacheOption[K, V] {
+	return func(o *cacheOptions[K, V]) {
+		o.onEvict = fn
+	}
+}
+
+// WithLoadTimeout 设置 GetOrLoad 中 loader 的超时时间，默认 30s。
+// loader 不受调用方 ctx 取消的影响，避免第一个调用方取消导致其他等待者一起失败
+func WithLoadTimeout[K comparable, V any](timeout time.Duration) CacheOption[K, V] {
+	return func(o *cacheOptions[K, V]) {
+		o.loadTimeout = timeout
+	}
+}
+
+// defaultLoadTimeout loader 的默认超时时间
+const defaultLoadTimeout = 30 * time.Second
+
+// CacheStats 缓存统计
+type CacheStats struct {
+	Hits        uint64
+	Misses      uint64
+	Loads       uint64
+	LoadErrors  uint64
+	Evictions   uint64
+	Expirations uint64
+}
+
+// HitRatio 命中率，没有访问时为 0
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+type cacheEntry[V any] struct {
+	value    V
+	expireAt time.Time
+	freq     int
+}
+
+func (e *cacheEntry[V]) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+type evicted[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictReason
+}
+
+// loadCall 正在进行的加载，同一个键的并发加载共享结果
+type loadCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	// stale 加载期间键被 Set、Delete 或 Clear 修改过，结果只返回给等待者，不写入缓存
+	stale bool
+}
+
+// Cache 支持 LRU/LFU 淘汰和过期时间的并发安全缓存:
+//
+//	cache := maputil.NewCache(maputil.WithCapacity[string, *User](1000), maputil.WithTTL[string, *User](time.Minute))
+//	user, err := cache.GetOrLoad(ctx, id, loadUser)
+//
+// 同一访问次数的条目保存在一个 OrderedMap 中，按访问时间排序，LRU 只使用一个访问次数
+type Cache[K comparable, V any] struct {
+	mu sync.Mutex
+
+	capacity int
+	policy   EvictionPolicy
+	ttl      time.Duration
+	onEvict  func(key K, value V, reason EvictReason)
+	now      func() time.Time
+	// loadTimeout GetOrLoad 中 loader 的超时时间
+	loadTimeout time.Duration
+
+	items   map[K]*cacheEntry[V]
+	buckets map[int]*OrderedMap[K, *cacheEntry[V]]
+	minFreq int
+	calls   map[K]*loadCall[V]
+
+	hits, misses, loads, loadErrors, evictions, expirations atomic.Uint64
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewCache 创建缓存
+func NewCache[K comparable, V any](opts ...CacheOption[K, V]) *Cache[K, V] {
+	var o cacheOptions[K, V]
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.loadTimeout <= 0 {
+		o.loadTimeout = defaultLoadTimeout
+	}
+	c := &Cache[K, V]{
+		capacity:    o.capacity,
+		policy:      o.policy,
+		ttl:         o.ttl,
+		onEvict:     o.onEvict,
+		now:         time.Now,
+		loadTimeout: o.loadTimeout,
+		items:       make(map[K]*cacheEntry[V]),
+		buckets:     make(map[int]*OrderedMap[K, *cacheEntry[V]]),
+		calls:       make(map[K]*loadCall[V]),
+		stop:        make(chan struct{}),
+	}
+	if o.cleanupInterval > 0 {
+		go c.cleanup(o.cleanupInterval)
+	}
+	return c
+}
+
+// Get 读取未过期的条目，同时更新访问顺序和次数
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	value, ok, expired := c.get(key)
+	c.mu.Unlock()
+
+	c.notify(expired)
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return value, ok
+}
+
+func (c *Cache[K, V]) get(key K) (value V, ok bool, expired []evicted[K, V]) {
+	entry, ok := c.items[key]
+	if !ok {
+		return value, false, nil
+	}
+	if entry.expired(c.now()) {
+		c.remove(key, entry)
+		c.expirations.Add(1)
+		return value, false, []evicted[K, V]{{key, entry.value, EvictExpired}}
+	}
+	c.touch(key, entry)
+	return entry.value, true, nil
+}
+
+// Set 写入条目，使用默认过期时间
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL 写入条目并指定过期时间，0 表示不过期
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	c.invalidate(key)
+	evictions := c.set(key, value, ttl)
+	c.mu.Unlock()
+
+	c.notify(evictions)
+}
+
+func (c *Cache[K, V]) set(key K, value V, ttl time.Duration) []evicted[K, V] {
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = c.now().Add(ttl)
+	}
+	if entry, ok := c.items[key]; ok {
+		entry.value, entry.expireAt = value, expireAt
+		c.touch(key, entry)
+		return nil
+	}
+
+	var evictions []evicted[K, V]
+	if c.capacity > 0 && len(c.items) >= c.capacity {
+		evictions = c.evict(len(c.items) - c.capacity + 1)
+	}
+	entry := &cacheEntry[V]{value: value, expireAt: expireAt, freq: 1}
+	c.items[key] = entry
+	c.bucket(1).Set(key, entry)
+	c.minFreq = 1
+	return evictions
+}
+
+// Delete 删除条目，条目存在时触发回调
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	c.invalidate(key)
+	entry, ok := c.items[key]
+	if ok {
+		c.remove(key, entry)
+	}
+	c.mu.Unlock()
+
+	if ok {
+		c.notify([]evicted[K, V]{{key, entry.value, EvictDeleted}})
+	}
+	return ok
+}
+
+// Clear 清空缓存，每个条目都会触发回调
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	removed := make([]evicted[K, V], 0, len(c.items))
+	for key, entry := range c.items {
+		removed = append(removed, evicted[K, V]{key, entry.value, EvictDeleted})
+	}
+	c.items = make(map[K]*cacheEntry[V])
+	c.buckets = make(map[int]*OrderedMap[K, *cacheEntry[V]])
+	c.minFreq = 0
+	for key := range c.calls {
+		c.invalidate(key)
+	}
+	c.mu.Unlock()
+
+	c.notify(removed)
+}
+
+// invalidate 标记键上正在进行的加载已过时，之后的 GetOrLoad 重新加载，调用方需持有锁
+func (c *Cache[K, V]) invalidate(key K) {
+	if call, ok := c.calls[key]; ok {
+		call.stale = true
+		delete(c.calls, key)
+	}
+}
+
+// Len 条目数量，包含已过期但尚未清理的条目
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
+
+// GetOrLoad 读取条目，不存在或已过期时调用 loader 加载并写入缓存。
+// 同一个键同时只有一个 loader 在执行，其他调用等待并共享结果，加载失败或 loader panic 时返回错误且不写入缓存。
+// loader 在独立的协程中执行，ctx 取消只让当前调用返回，loader 的超时由 WithLoadTimeout 控制；
+// 加载期间键被 Set、Delete 或 Clear 修改过时，加载结果不写入缓存
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader func(ctx context.Context, key K) (V, error)) (V, error) {
+	c.mu.Lock()
+	value, ok, expired := c.get(key)
+	if ok {
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return value, nil
+	}
+	c.misses.Add(1)
+	call, loading := c.calls[key]
+	if !loading {
+		call = &loadCall[V]{done: make(chan struct{})}
+		c.calls[key] = call
+	}
+	c.mu.Unlock()
+	c.notify(expired)
+
+	if !loading {
+		go c.load(ctx, key, call, loader)
+	}
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (c *Cache[K, V]) load(ctx context.Context, key K, call *loadCall[V], loader func(ctx context.Context, key K) (V, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("maputil: cache loader panic: %v", r)
+		}
+		var evictions []evicted[K, V]
+		c.mu.Lock()
+		if !call.stale {
+			delete(c.calls, key)
+			if call.err == nil {
+				evictions = c.set(key, call.value, c.ttl)
+			}
+		}
+		c.mu.Unlock()
+
+		c.loads.Add(1)
+		if call.err != nil {
+			c.loadErrors.Add(1)
+		}
+		close(call.done)
+		c.notify(evictions)
+	}()
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.loadTimeout)
+	defer cancel()
+	call.value, call.err = loader(ctx, key)
+}
+
+// Stats 返回统计信息
+func (c *Cache[K, V]) Stats() CacheStats {
+	return CacheStats{
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Loads:       c.loads.Load(),
+		LoadErrors:  c.loadErrors.Load(),
+		Evictions:   c.evictions.Load(),
+		Expirations: c.expirations.Load(),
+	}
+}
+
+// DeleteExpired 清理所有过期条目，返回清理数量
+func (c *Cache[K, V]) DeleteExpired() int {
+	c.mu.Lock()
+	now := c.now()
+	var expired []evicted[K, V]
+	for key, entry := range c.items {
+		if entry.expired(now) {
+			c.remove(key, entry)
+			expired = append(expired, evicted[K, V]{key, entry.value, EvictExpired})
+		}
+	}
+	c.mu.Unlock()
+
+	c.expirations.Add(uint64(len(expired)))
+	c.notify(expired)
+	return len(expired)
+}
+
+// Close 停止后台清理协程
+func (c *Cache[K, V]) Close() {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+func (c *Cache[K, V]) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.DeleteExpired()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *Cache[K, V]) bucket(freq int) *OrderedMap[K, *cacheEntry[V]] {
+	b, ok := c.buckets[freq]
+	if !ok {
+		b = NewOrderedMap[K, *cacheEntry[V]]()
+		c.buckets[freq] = b
+	}
+	return b
+}
+
+// touch 记录一次访问：LRU 移动到末尾，LFU 移动到下一个访问次数
+func (c *Cache[K, V]) touch(key K, entry *cacheEntry[V]) {
+	if c.policy == LRU {
+		c.buckets[entry.freq].MoveToBack(key)
+		return
+	}
+	c.unlink(key, entry)
+	entry.freq++
+	c.bucket(entry.freq).Set(key, entry)
+	if c.minFreq == entry.freq-1 && c.buckets[entry.freq-1] == nil {
+		c.minFreq = entry.freq
+	}
+}
+
+// unlink 从访问次数分组中移除条目，分组为空时删除分组
+func (c *Cache[K, V]) unlink(key K, entry *cacheEntry[V]) {
+	b := c.buckets[entry.freq]
+	b.Delete(key)
+	if b.Len() == 0 {
+		delete(c.buckets, entry.freq)
+	}
+}
+
+func (c *Cache[K, V]) remove(key K, entry *cacheEntry[V]) {
+	c.unlink(key, entry)
+	delete(c.items, key)
+	if len(c.items) == 0 {
+		c.minFreq = 0
+	} else if _, ok := c.buckets[c.minFreq]; !ok {
+		c.minFreq = c.lowestFreq()
+	}
+}
+
+// evict 按淘汰策略淘汰 n 个条目，被淘汰的条目已过期时按过期处理
+func (c *Cache[K, V]) evict(n int) []evicted[K, V] {
+	evictions := make([]evicted[K, V], 0, n)
+	for len(evictions) < n && len(c.items) > 0 {
+		front, _ := c.buckets[c.minFreq].Front()
+		key, entry := front.Key, front.Value
+		c.remove(key, entry)
+		reason := EvictCapacity
+		if entry.expired(c.now()) {
+			reason = EvictExpired
+			c.expirations.Add(1)
+		} else {
+			c.evictions.Add(1)
+		}
+		evictions = append(evictions, evicted[K, V]{key, entry.value, reason})
+	}
+	return evictions
+}
+
+func (c *Cache[K, V]) lowestFreq() int {
+	lowest := 0
+	for freq := range c.buckets {
+		if lowest == 0 || freq < lowest {
+			lowest = freq
+		}
+	}
+	return lowest
+}
+
+func (c *Cache[K, V]) notify(evictions []evicted[K, V]) {
+	if c.onEvict == nil {
+		return
+	}
+	for _, e := range evictions {
+		c.onEvict(e.key, e.value, e.reason)
+	}
+}
@@ -0,0 +1,250 @@
+package maputil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_LRU(t *testing.T) {
+	var evicted []string
+	c := NewCache(WithCapacity[string, int](2), WithEvictCallback(func(key string, value int, reason EvictReason) {
+		assert.Equal(t, EvictCapacity, reason)
+		evicted = append(evicted, key)
+	}))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+	c.Set("c", 3)
+	assert.Equal(t, []string{"b"}, evicted)
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	// 更新已存在的键不会淘汰
+	c.Set("a", 10)
+	value, _ := c.Get("a")
+	assert.Equal(t, 10, value)
+	assert.Equal(t, []string{"b"}, evicted)
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Evictions)
+	assert.InDelta(t, 2.0/3, stats.HitRatio(), 1e-9)
+}
+
+func TestCache_LFU(t *testing.T) {
+	c := NewCache(WithCapacity[string, int](3), WithEvictionPolicy[string, int](LFU))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	for i := 0; i < 3; i++ {
+		c.Get("a")
+	}
+	c.Get("b")
+	c.Get("c")
+	// b 和 c 访问次数相同，淘汰最久未访问的 b
+	c.Set("d", 4)
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+	// 新条目 d 访问次数最少
+	c.Set("e", 5)
+	_, ok = c.Get("d")
+	assert.False(t, ok)
+	for _, key := range []string{"a", "c", "e"} {
+		_, ok := c.Get(key)
+		assert.True(t, ok, key)
+	}
+}
+
+func TestCache_TTL(t *testing.T) {
+	now := time.Now()
+	var reasons []EvictReason
+	c := NewCache(WithTTL[string, int](time.Minute), WithEvictCallback(func(_ string, _ int, reason EvictReason) {
+		reasons = append(reasons, reason)
+	}))
+	c.now = func() time.Time { return now }
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, time.Hour)
+	c.SetWithTTL("c", 3, 0)
+
+	now = now.Add(2 * time.Minute)
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, []EvictReason{EvictExpired}, reasons)
+
+	now = now.Add(2 * time.Hour)
+	assert.Equal(t, 1, c.DeleteExpired())
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), c.Stats().Expirations)
+
+	assert.True(t, c.Delete("c"))
+	assert.False(t, c.Delete("c"))
+	assert.Equal(t, EvictDeleted, reasons[len(reasons)-1])
+}
+
+func TestCache_BackgroundCleanup(t *testing.T) {
+	expired := make(chan string, 1)
+	c := NewCache(WithCleanupInterval[string, int](5*time.Millisecond), WithEvictCallback(func(key string, _ int, _ EvictReason) {
+		expired <- key
+	}))
+	defer c.Close()
+	c.SetWithTTL("a", 1, time.Millisecond)
+	select {
+	case key := <-expired:
+		assert.Equal(t, "a", key)
+	case <-time.After(time.Second):
+		t.Fatal("entry not expired by background cleanup")
+	}
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	c := NewCache[string, int]()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (int, error) {
+		calls.Add(1)
+		<-release
+		return len(key), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := c.GetOrLoad(context.Background(), "abc", loader)
+			assert.NoError(t, err)
+			assert.Equal(t, 3, value)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+
+	value, err := c.GetOrLoad(context.Background(), "abc", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, value)
+	assert.Equal(t, int32(1), calls.Load())
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.Loads)
+	assert.Equal(t, uint64(11), stats.Hits+stats.Misses)
+}
+
+func TestCache_GetOrLoadCallerCanceled(t *testing.T) {
+	c := NewCache[string, int]()
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (int, error) {
+		select {
+		case <-release:
+			return len(key), nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.GetOrLoad(ctx, "abc", loader)
+		first <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	waiter := make(chan int, 1)
+	go func() {
+		value, err := c.GetOrLoad(context.Background(), "abc", loader)
+		assert.NoError(t, err)
+		waiter <- value
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// 第一个调用方取消不影响 loader 和其他等待者
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+	close(release)
+	assert.Equal(t, 3, <-waiter)
+	value, ok := c.Get("abc")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+}
+
+func TestCache_GetOrLoadTimeout(t *testing.T) {
+	c := NewCache(WithLoadTimeout[string, int](20 * time.Millisecond))
+	_, err := c.GetOrLoad(context.Background(), "a", func(ctx context.Context, key string) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestCache_GetOrLoadModified(t *testing.T) {
+	c := NewCache[string, int]()
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (int, error) {
+		started <- struct{}{}
+		<-release
+		return 1, nil
+	}
+
+	tests := []struct {
+		name   string
+		modify func()
+		want   int
+		ok     bool
+	}{
+		{"set", func() { c.Set("a", 2) }, 2, true},
+		{"delete", func() { c.Delete("a") }, 0, false},
+		{"clear", c.Clear, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.Clear()
+			release = make(chan struct{})
+			done := make(chan int, 1)
+			go func() {
+				value, err := c.GetOrLoad(context.Background(), "a", loader)
+				assert.NoError(t, err)
+				done <- value
+			}()
+			<-started
+
+			// 加载期间键被修改，加载结果只返回给调用方，不覆盖缓存
+			tt.modify()
+			close(release)
+			assert.Equal(t, 1, <-done)
+			value, ok := c.Get("a")
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, value)
+		})
+	}
+}
+
+func TestCache_GetOrLoadError(t *testing.T) {
+	c := NewCache[string, int]()
+	errLoad := errors.New("load failed")
+	_, err := c.GetOrLoad(context.Background(), "a", func(context.Context, string) (int, error) {
+		return 0, errLoad
+	})
+	assert.ErrorIs(t, err, errLoad)
+	assert.Equal(t, 0, c.Len())
+
+	_, err = c.GetOrLoad(context.Background(), "a", func(context.Context, string) (int, error) {
+		panic("boom")
+	})
+	assert.ErrorContains(t, err, "boom")
+	assert.Equal(t, uint64(2), c.Stats().LoadErrors)
+}
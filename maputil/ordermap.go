@@ -1,6 +1,7 @@
 package maputil
 
 import (
+	"bytes"
 	"container/list"
 	"encoding/json"
 	"fmt"
@@ -10,39 +11,239 @@ import (
 	"sync"
 )
 
+// UpdateMode Set 更新已存在的键时的位置策略
+type UpdateMode int
+
+const (
+	// UpdateMoveToBack 更新后移动到末尾，默认策略
+	UpdateMoveToBack UpdateMode = iota
+	// UpdateInPlace 更新时保持原有位置，即保持首次插入顺序
+	UpdateInPlace
+)
+
+type orderedMapOptions struct {
+	updateMode UpdateMode
+}
+
+// OrderedMapOption OrderedMap 选项
+type OrderedMapOption func(o *orderedMapOptions)
+
+// WithUpdateMode 设置 Set 更新已存在的键时的位置策略
+func WithUpdateMode(mode UpdateMode) OrderedMapOption {
+	return func(o *orderedMapOptions) {
+		o.updateMode = mode
+	}
+}
+
 type OrderedMap[K comparable, V any] struct {
 	mu sync.RWMutex
 
-	data  map[K]V
-	order *list.List
-	index map[K]*list.Element
+	data       map[K]V
+	order      *list.List
+	index      map[K]*list.Element
+	updateMode UpdateMode
 }
 
-func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+func NewOrderedMap[K comparable, V any](opts ...OrderedMapOption) *OrderedMap[K, V] {
+	var o orderedMapOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return &OrderedMap[K, V]{
-		data:  make(map[K]V),
-		order: list.New(),
-		index: make(map[K]*list.Element),
+		data:       make(map[K]V),
+		order:      list.New(),
+		index:      make(map[K]*list.Element),
+		updateMode: o.updateMode,
 	}
 }
 
+// Set 设置键值，新键追加到末尾，已存在的键按 UpdateMode 决定是否移动到末尾
 func (om *OrderedMap[K, V]) Set(key K, value V) {
 	om.mu.Lock()
 	defer om.mu.Unlock()
 
+	om.set(key, value)
+}
+
+func (om *OrderedMap[K, V]) set(key K, value V) {
+	om.data[key] = value
+
 	if elem, ok := om.index[key]; ok {
-		elem.Value = value
-		om.order.MoveToBack(elem)
+		if om.updateMode == UpdateMoveToBack {
+			om.order.MoveToBack(elem)
+		}
 
 		return
 	}
 
-	om.data[key] = value
-
 	elem := om.order.PushBack(key)
 	om.index[key] = elem
 }
 
+// InsertBefore 将键值插入到 mark 之前，键已存在时更新值并移动到该位置，mark 不存在或与 key 相同时返回 false
+func (om *OrderedMap[K, V]) InsertBefore(mark, key K, value V) bool {
+	return om.insert(mark, key, value, func(elem, markElem *list.Element) *list.Element {
+		if elem == nil {
+			return om.order.InsertBefore(key, markElem)
+		}
+		om.order.MoveBefore(elem, markElem)
+		return elem
+	})
+}
+
+// InsertAfter 将键值插入到 mark 之后，键已存在时更新值并移动到该位置，mark 不存在或与 key 相同时返回 false
+func (om *OrderedMap[K, V]) InsertAfter(mark, key K, value V) bool {
+	return om.insert(mark, key, value, func(elem, markElem *list.Element) *list.Element {
+		if elem == nil {
+			return om.order.InsertAfter(key, markElem)
+		}
+		om.order.MoveAfter(elem, markElem)
+		return elem
+	})
+}
+
+func (om *OrderedMap[K, V]) insert(mark, key K, value V, place func(elem, markElem *list.Element) *list.Element) bool {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	markElem, ok := om.index[mark]
+	if !ok || mark == key {
+		return false
+	}
+
+	om.data[key] = value
+	om.index[key] = place(om.index[key], markElem)
+
+	return true
+}
+
+// MoveToFront 将键移动到开头，键不存在时返回 false
+func (om *OrderedMap[K, V]) MoveToFront(key K) bool {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	elem, ok := om.index[key]
+	if ok {
+		om.order.MoveToFront(elem)
+	}
+
+	return ok
+}
+
+// MoveToBack 将键移动到末尾，键不存在时返回 false
+func (om *OrderedMap[K, V]) MoveToBack(key K) bool {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	elem, ok := om.index[key]
+	if ok {
+		om.order.MoveToBack(elem)
+	}
+
+	return ok
+}
+
+// At 返回第 i 个键值，从 0 开始，越界时返回 false，时间复杂度 O(n)
+func (om *OrderedMap[K, V]) At(i int) (struct {
+	Key   K
+	Value V
+}, bool) {
+	om.mu.RLock()
+	defer om.mu.RUnlock()
+
+	n := om.order.Len()
+	if i < 0 || i >= n {
+		return struct {
+			Key   K
+			Value V
+		}{}, false
+	}
+
+	// 从距离较近的一端开始查找
+	var elem *list.Element
+	if i < n/2 {
+		elem = om.order.Front()
+		for ; i > 0; i-- {
+			elem = elem.Next()
+		}
+	} else {
+		elem = om.order.Back()
+		for j := n - 1; j > i; j-- {
+			elem = elem.Prev()
+		}
+	}
+
+	key := elem.Value.(K)
+
+	return struct {
+		Key   K
+		Value V
+	}{Key: key, Value: om.data[key]}, true
+}
+
+// IndexOf 返回键的位置，从 0 开始，键不存在时返回 -1，时间复杂度 O(n)
+func (om *OrderedMap[K, V]) IndexOf(key K) int {
+	om.mu.RLock()
+	defer om.mu.RUnlock()
+
+	target, ok := om.index[key]
+	if !ok {
+		return -1
+	}
+
+	i := 0
+	for elem := om.order.Front(); elem != target; elem = elem.Next() {
+		i++
+	}
+
+	return i
+}
+
+// PopFront 删除并返回第一个键值，为空时返回 false
+func (om *OrderedMap[K, V]) PopFront() (struct {
+	Key   K
+	Value V
+}, bool) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	return om.pop(om.order.Front())
+}
+
+// PopBack 删除并返回最后一个键值，为空时返回 false
+func (om *OrderedMap[K, V]) PopBack() (struct {
+	Key   K
+	Value V
+}, bool) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	return om.pop(om.order.Back())
+}
+
+func (om *OrderedMap[K, V]) pop(elem *list.Element) (struct {
+	Key   K
+	Value V
+}, bool) {
+	if elem == nil {
+		return struct {
+			Key   K
+			Value V
+		}{}, false
+	}
+
+	key := elem.Value.(K)
+	value := om.data[key]
+	om.order.Remove(elem)
+	delete(om.data, key)
+	delete(om.index, key)
+
+	return struct {
+		Key   K
+		Value V
+	}{Key: key, Value: value}, true
+}
+
 func (om *OrderedMap[K, V]) Get(key K) (V, bool) {
 	om.mu.RLock()
 	defer om.mu.RUnlock()
@@ -205,6 +406,10 @@ func (om *OrderedMap[K, V]) Elements() []struct {
 	return elements
 }
 
+// Iter 通过通道按顺序遍历，遍历期间后台协程持有读锁，循环体中调用 Set、Delete 等写操作会死锁，
+// 提前退出时协程和读锁不会释放。
+//
+// Deprecated: 使用 All，All 基于快照遍历，不持有锁，循环体中可以读写 map
 func (om *OrderedMap[K, V]) Iter() <-chan struct {
 	Key   K
 	Value V
@@ -233,6 +438,10 @@ func (om *OrderedMap[K, V]) Iter() <-chan struct {
 	return ch
 }
 
+// ReverseIter 通过通道逆序遍历，遍历期间后台协程持有读锁，循环体中调用 Set、Delete 等写操作会死锁，
+// 提前退出时协程和读锁不会释放。
+//
+// Deprecated: 使用 Backward，Backward 基于快照遍历，不持有锁，循环体中可以读写 map
 func (om *OrderedMap[K, V]) ReverseIter() <-chan struct {
 	Key   K
 	Value V
@@ -282,47 +491,131 @@ func (om *OrderedMap[K, V]) SortByKey(less func(a, b K) bool) {
 	}
 }
 
+// MarshalJSON 按插入顺序输出键值，值为 OrderedMap 时同样保持顺序
 func (om *OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
 	om.mu.RLock()
 	defer om.mu.RUnlock()
 
-	tempMap := make(map[string]V)
+	var buf bytes.Buffer
+	buf.WriteByte('{')
 	for e := om.order.Front(); e != nil; e = e.Next() {
 		key := e.Value.(K)
 		keyStr, err := keyToString(key)
 		if err != nil {
 			return nil, err
 		}
-		tempMap[keyStr] = om.data[key]
+		if e != om.order.Front() {
+			buf.WriteByte(',')
+		}
+		keyBytes, err := json.Marshal(keyStr)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(keyBytes)
+		buf.WriteByte(':')
+		valueBytes, err := json.Marshal(om.data[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(valueBytes)
 	}
+	buf.WriteByte('}')
 
-	return json.Marshal(tempMap)
+	return buf.Bytes(), nil
 }
 
+// UnmarshalJSON 按文档中的顺序读取键值，重复的键按 UpdateMode 处理。
+// V 为 any 时嵌套对象解析为 *OrderedMap[string, any]，同样保持顺序
 func (om *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
 	om.mu.Lock()
 	defer om.mu.Unlock()
 
-	tempMap := make(map[string]V)
-	if err := json.Unmarshal(data, &tempMap); err != nil {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
 		return err
 	}
+	if tok == nil {
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("maputil: cannot unmarshal %v into OrderedMap", tok)
+	}
 
 	om.data = make(map[K]V)
+	if om.order == nil {
+		om.order = list.New()
+	}
 	om.order.Init()
 	om.index = make(map[K]*list.Element)
 
-	for keyStr, value := range tempMap {
-		key, err := stringToKey[K](keyStr)
+	_, anyValue := any((*V)(nil)).(*any)
+	for dec.More() {
+		tok, err := dec.Token()
 		if err != nil {
 			return err
 		}
-		om.data[key] = value
-		elem := om.order.PushBack(key)
-		om.index[key] = elem
+		key, err := stringToKey[K](tok.(string))
+		if err != nil {
+			return err
+		}
+		var value V
+		if anyValue {
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return err
+			}
+			if v != nil {
+				value = v.(V)
+			}
+		} else if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		om.set(key, value)
 	}
+	_, err = dec.Token()
 
-	return nil
+	return err
+}
+
+// decodeOrdered 读取任意 JSON 值，对象解析为 *OrderedMap[string, any]，数组解析为 []any
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		om := NewOrderedMap[string, any](WithUpdateMode(UpdateInPlace))
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			om.set(tok.(string), value)
+		}
+		_, err = dec.Token()
+		return om, err
+	default:
+		values := make([]any, 0)
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		_, err = dec.Token()
+		return values, err
+	}
 }
 
 func keyToString[K any](key K) (string, error) {
@@ -0,0 +1,98 @@
+package maputil
+
+import (
+	"container/list"
+	"iter"
+)
+
+// All 按顺序遍历键值，用于 range-over-func:
+//
+//	for key, value := range om.All() {
+//		...
+//	}
+//
+// 开始遍历时在读锁内复制键值，遍历期间不持有锁，循环体中可以读写 map，修改不会影响本次遍历
+func (om *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		om.walk(om.collect(func() *list.Element { return om.order.Front() }, (*list.Element).Next), yield)
+	}
+}
+
+// Backward 逆序遍历键值，与 All 相同基于开始遍历时的快照
+func (om *OrderedMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		om.walk(om.collect(func() *list.Element { return om.order.Back() }, (*list.Element).Prev), yield)
+	}
+}
+
+// From 从指定键开始（包含该键）按顺序遍历，键不存在时不遍历，与 All 相同基于开始遍历时的快照
+func (om *OrderedMap[K, V]) From(key K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		om.walk(om.collect(func() *list.Element { return om.index[key] }, (*list.Element).Next), yield)
+	}
+}
+
+// BackwardFrom 从指定键开始（包含该键）逆序遍历，键不存在时不遍历，与 All 相同基于开始遍历时的快照
+func (om *OrderedMap[K, V]) BackwardFrom(key K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		om.walk(om.collect(func() *list.Element { return om.index[key] }, (*list.Element).Prev), yield)
+	}
+}
+
+// KeysSeq 按顺序遍历键，与 All 相同基于开始遍历时的快照
+func (om *OrderedMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key := range om.All() {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesSeq 按顺序遍历值，与 All 相同基于开始遍历时的快照
+func (om *OrderedMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, value := range om.All() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Snapshot 调用时复制当前所有键值，返回的迭代器可以多次遍历，结果相同。
+// 遍历期间不持有锁，循环体中可以修改 map，修改不会影响遍历
+func (om *OrderedMap[K, V]) Snapshot() iter.Seq2[K, V] {
+	entries := om.collect(func() *list.Element { return om.order.Front() }, (*list.Element).Next)
+	return func(yield func(K, V) bool) {
+		om.walk(entries, yield)
+	}
+}
+
+type orderedEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// collect 在读锁内从 start 返回的元素开始沿 next 方向复制键值
+func (om *OrderedMap[K, V]) collect(start func() *list.Element, next func(*list.Element) *list.Element) []orderedEntry[K, V] {
+	om.mu.RLock()
+	defer om.mu.RUnlock()
+
+	var entries []orderedEntry[K, V]
+	for elem := start(); elem != nil; elem = next(elem) {
+		key := elem.Value.(K)
+		entries = append(entries, orderedEntry[K, V]{key, om.data[key]})
+	}
+	return entries
+}
+
+// walk 依次将复制的键值交给 yield，不持有锁
+func (om *OrderedMap[K, V]) walk(entries []orderedEntry[K, V], yield func(K, V) bool) {
+	for _, e := range entries {
+		if !yield(e.key, e.value) {
+			return
+		}
+	}
+}
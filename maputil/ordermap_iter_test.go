@@ -0,0 +1,75 @@
+package maputil
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newIterMap() *OrderedMap[string, int] {
+	om := NewOrderedMap[string, int]()
+	om.Set("a", 1)
+	om.Set("b", 2)
+	om.Set("c", 3)
+	om.Set("d", 4)
+	return om
+}
+
+func collect(seq func(yield func(string, int) bool)) ([]string, []int) {
+	var keys []string
+	var values []int
+	for key, value := range seq {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	return keys, values
+}
+
+func TestOrderedMap_All_Backward(t *testing.T) {
+	om := newIterMap()
+	keys, values := collect(om.All())
+	assert.Equal(t, []string{"a", "b", "c", "d"}, keys)
+	assert.Equal(t, []int{1, 2, 3, 4}, values)
+
+	keys, _ = collect(om.Backward())
+	assert.Equal(t, []string{"d", "c", "b", "a"}, keys)
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, slices.Collect(om.KeysSeq()))
+	assert.Equal(t, []int{1, 2, 3, 4}, slices.Collect(om.ValuesSeq()))
+
+	// 遍历期间不持有锁，循环体中可以读写 map，修改不影响本次遍历
+	keys = nil
+	for key, value := range om.All() {
+		keys = append(keys, key)
+		current, _ := om.Get(key)
+		om.Set(key, current+value)
+		om.Set(key+key, 0)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d"}, keys)
+	value, _ := om.Get("c")
+	assert.Equal(t, 6, value)
+	assert.Equal(t, 8, om.Len())
+}
+
+func TestOrderedMap_From(t *testing.T) {
+	om := newIterMap()
+	keys, _ := collect(om.From("b"))
+	assert.Equal(t, []string{"b", "c", "d"}, keys)
+	keys, _ = collect(om.BackwardFrom("b"))
+	assert.Equal(t, []string{"b", "a"}, keys)
+	keys, _ = collect(om.From("x"))
+	assert.Empty(t, keys)
+}
+
+func TestOrderedMap_Snapshot(t *testing.T) {
+	om := newIterMap()
+	var keys []string
+	for key := range om.Snapshot() {
+		keys = append(keys, key)
+		om.Delete(key)
+		om.Set(key+key, 0)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d"}, keys)
+	assert.Equal(t, []string{"aa", "bb", "cc", "dd"}, om.Keys())
+}
@@ -1,6 +1,7 @@
 package maputil
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -154,3 +155,138 @@ func TestOrderedMap_Front_Back(t *testing.T) {
 	assert.Equal(t, 3, backElement.Value)
 	assert.Equal(t, true, ok)
 }
+
+func TestOrderedMap_UpdateMode(t *testing.T) {
+	om := NewOrderedMap[string, int]()
+	om.Set("a", 1)
+	om.Set("b", 2)
+	om.Set("a", 10)
+	assert.Equal(t, []string{"b", "a"}, om.Keys())
+	val, _ := om.Get("a")
+	assert.Equal(t, 10, val)
+	back, _ := om.Back()
+	assert.Equal(t, "a", back.Key)
+	assert.Equal(t, 10, back.Value)
+
+	om = NewOrderedMap[string, int](WithUpdateMode(UpdateInPlace))
+	om.Set("a", 1)
+	om.Set("b", 2)
+	om.Set("a", 10)
+	assert.Equal(t, []string{"a", "b"}, om.Keys())
+	assert.Equal(t, []int{10, 2}, om.Values())
+}
+
+func TestOrderedMap_Insert(t *testing.T) {
+	om := NewOrderedMap[string, int]()
+	om.Set("a", 1)
+	om.Set("c", 3)
+	assert.True(t, om.InsertBefore("c", "b", 2))
+	assert.True(t, om.InsertAfter("c", "d", 4))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, om.Keys())
+
+	// 已存在的键移动到新位置并更新值
+	assert.True(t, om.InsertBefore("a", "d", 40))
+	assert.Equal(t, []string{"d", "a", "b", "c"}, om.Keys())
+	assert.Equal(t, []int{40, 1, 2, 3}, om.Values())
+
+	assert.False(t, om.InsertAfter("x", "e", 5))
+	assert.False(t, om.InsertAfter("a", "a", 5))
+	assert.Equal(t, 4, om.Len())
+}
+
+func TestOrderedMap_Move(t *testing.T) {
+	om := NewOrderedMap[string, int]()
+	om.Set("a", 1)
+	om.Set("b", 2)
+	om.Set("c", 3)
+	assert.True(t, om.MoveToFront("c"))
+	assert.Equal(t, []string{"c", "a", "b"}, om.Keys())
+	assert.True(t, om.MoveToBack("c"))
+	assert.Equal(t, []string{"a", "b", "c"}, om.Keys())
+	assert.False(t, om.MoveToFront("x"))
+}
+
+func TestOrderedMap_At_IndexOf(t *testing.T) {
+	om := NewOrderedMap[string, int]()
+	for i, key := range []string{"a", "b", "c", "d", "e"} {
+		om.Set(key, i)
+	}
+	for i, key := range om.Keys() {
+		elem, ok := om.At(i)
+		assert.True(t, ok)
+		assert.Equal(t, key, elem.Key)
+		assert.Equal(t, i, elem.Value)
+		assert.Equal(t, i, om.IndexOf(key))
+	}
+	_, ok := om.At(5)
+	assert.False(t, ok)
+	_, ok = om.At(-1)
+	assert.False(t, ok)
+	assert.Equal(t, -1, om.IndexOf("x"))
+}
+
+func TestOrderedMap_Pop(t *testing.T) {
+	om := NewOrderedMap[string, int]()
+	om.Set("a", 1)
+	om.Set("b", 2)
+	om.Set("c", 3)
+	front, ok := om.PopFront()
+	assert.True(t, ok)
+	assert.Equal(t, "a", front.Key)
+	assert.Equal(t, 1, front.Value)
+	back, ok := om.PopBack()
+	assert.True(t, ok)
+	assert.Equal(t, "c", back.Key)
+	assert.Equal(t, []string{"b"}, om.Keys())
+	assert.False(t, om.ContainKey("a"))
+	_, _ = om.PopBack()
+	_, ok = om.PopFront()
+	assert.False(t, ok)
+	assert.Equal(t, 0, om.Len())
+}
+
+func TestOrderedMap_JSONOrder(t *testing.T) {
+	om := NewOrderedMap[string, int]()
+	om.Set("z", 1)
+	om.Set("a", 2)
+	om.Set("m", 3)
+	data, err := json.Marshal(om)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"z":1,"a":2,"m":3}`, string(data))
+
+	decoded := NewOrderedMap[string, int]()
+	assert.NoError(t, json.Unmarshal([]byte(`{"z":1,"a":2,"m":3}`), decoded))
+	assert.Equal(t, []string{"z", "a", "m"}, decoded.Keys())
+	assert.Equal(t, []int{1, 2, 3}, decoded.Values())
+
+	keys := NewOrderedMap[int, string]()
+	assert.NoError(t, json.Unmarshal([]byte(`{"3":"c","1":"a"}`), keys))
+	assert.Equal(t, []int{3, 1}, keys.Keys())
+}
+
+func TestOrderedMap_JSONNested(t *testing.T) {
+	src := `{"server":{"port":8080,"host":"localhost"},"tags":["b","a"],"extra":null,"debug":true}`
+	om := NewOrderedMap[string, any]()
+	assert.NoError(t, json.Unmarshal([]byte(src), om))
+	assert.Equal(t, []string{"server", "tags", "extra", "debug"}, om.Keys())
+	server, _ := om.Get("server")
+	assert.Equal(t, []string{"port", "host"}, server.(*OrderedMap[string, any]).Keys())
+	data, err := json.Marshal(om)
+	assert.NoError(t, err)
+	assert.Equal(t, src, string(data))
+
+	// 结构体字段中的嵌套 OrderedMap
+	type config struct {
+		Sections *OrderedMap[string, *OrderedMap[string, int]] `json:"sections"`
+	}
+	var c config
+	assert.NoError(t, json.Unmarshal([]byte(`{"sections":{"b":{"y":1,"x":2},"a":{}}}`), &c))
+	assert.Equal(t, []string{"b", "a"}, c.Sections.Keys())
+	b, _ := c.Sections.Get("b")
+	assert.Equal(t, []string{"y", "x"}, b.Keys())
+	data, err = json.Marshal(c)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"sections":{"b":{"y":1,"x":2},"a":{}}}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`[1,2]`), NewOrderedMap[string, int]()))
+}
@@ -0,0 +1,280 @@
+package maputil
+
+import (
+	"fmt"
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"reflect"
+	"sync"
+)
+
+const defaultShardCount = 32
+
+type shardedMapOptions[K comparable, V any] struct {
+	shards int
+	hasher func(key K) uint64
+}
+
+// ShardedMapOption ShardedMap 选项，键值类型与 ShardedMap 一致，类型不匹配时编译报错:
+//
+//	maputil.NewShardedMap(maputil.WithShardCount[string, int](64))
+type ShardedMapOption[K comparable, V any] func(o *shardedMapOptions[K, V])
+
+// WithShardCount 设置分片数量，向上取整为 2 的幂，默认 32
+func WithShardCount[K comparable, V any](n int) ShardedMapOption[K, V] {
+	return func(o *shardedMapOptions[K, V]) {
+		o.shards = n
+	}
+}
+
+// WithHasher 设置键的哈希函数，键为结构体等非基础类型时建议设置以避免按格式化字符串哈希。
+// 相等的键必须返回相同的哈希值
+func WithHasher[K comparable, V any](fn func(key K) uint64) ShardedMapOption[K, V] {
+	return func(o *shardedMapOptions[K, V]) {
+		o.hasher = fn
+	}
+}
+
+type shard[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  map[K]V
+}
+
+// ShardedMap 分片加锁的并发安全 map，适用于读写都很频繁的场景，每个分片有独立的读写锁。
+// 遍历基于快照，遍历期间不持有锁，回调中可以读写 map
+type ShardedMap[K comparable, V any] struct {
+	shards []*shard[K, V]
+	mask   uint64
+	hash   func(key K) uint64
+}
+
+// NewShardedMap 创建分片 map
+func NewShardedMap[K comparable, V any](opts ...ShardedMapOption[K, V]) *ShardedMap[K, V] {
+	o := shardedMapOptions[K, V]{shards: defaultShardCount, hasher: defaultHasher[K]()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	n := 1
+	if o.shards > 1 {
+		n = 1 << bits.Len(uint(o.shards-1))
+	}
+	sm := &ShardedMap[K, V]{
+		shards: make([]*shard[K, V], n),
+		mask:   uint64(n - 1),
+		hash:   o.hasher,
+	}
+	for i := range sm.shards {
+		sm.shards[i] = &shard[K, V]{m: make(map[K]V)}
+	}
+	return sm
+}
+
+// defaultHasher 字符串和数值类型直接哈希，其他类型按 %#v 格式化后哈希。
+// 包含浮点数的结构体按格式化字符串哈希时 -0 与 +0 不一致，需要通过 WithHasher 设置哈希函数
+func defaultHasher[K comparable]() func(key K) uint64 {
+	seed := maphash.MakeSeed()
+	return func(key K) uint64 {
+		switch v := any(key).(type) {
+		case string:
+			return maphash.String(seed, v)
+		case int:
+			return mix(uint64(v))
+		case int64:
+			return mix(uint64(v))
+		case uint64:
+			return mix(v)
+		}
+		rv := reflect.ValueOf(key)
+		switch rv.Kind() {
+		case reflect.String:
+			return maphash.String(seed, rv.String())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return mix(uint64(rv.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return mix(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			return hashFloat(rv.Float())
+		case reflect.Complex64, reflect.Complex128:
+			c := rv.Complex()
+			return hashFloat(real(c)) ^ bits.RotateLeft64(hashFloat(imag(c)), 32)
+		}
+		return maphash.String(seed, fmt.Sprintf("%#v", key))
+	}
+}
+
+// hashFloat 哈希浮点数，-0 与 +0 作为 map 键相等，哈希前统一为 +0
+func hashFloat(f float64) uint64 {
+	if f == 0 {
+		f = 0
+	}
+	return mix(math.Float64bits(f))
+}
+
+// mix 打散整数键，避免连续整数落在相邻分片（splitmix64）
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func (sm *ShardedMap[K, V]) shard(key K) *shard[K, V] {
+	return sm.shards[sm.hash(key)&sm.mask]
+}
+
+// Get 读取键值
+func (sm *ShardedMap[K, V]) Get(key K) (V, bool) {
+	s := sm.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.m[key]
+	return value, ok
+}
+
+// Set 设置键值
+func (sm *ShardedMap[K, V]) Set(key K, value V) {
+	s := sm.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.m[key] = value
+}
+
+// Delete 删除键，返回键是否存在
+func (sm *ShardedMap[K, V]) Delete(key K) bool {
+	s := sm.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.m[key]
+	delete(s.m, key)
+	return ok
+}
+
+// ContainKey 判断键是否存在
+func (sm *ShardedMap[K, V]) ContainKey(key K) bool {
+	_, ok := sm.Get(key)
+	return ok
+}
+
+// GetOrSet 键存在时返回已有值和 true，否则写入 value 并返回 value 和 false
+func (sm *ShardedMap[K, V]) GetOrSet(key K, value V) (V, bool) {
+	s := sm.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if actual, ok := s.m[key]; ok {
+		return actual, true
+	}
+	s.m[key] = value
+	return value, false
+}
+
+// Update 键存在时以 fn 的返回值替换原值，返回新值和键是否存在，fn 在分片锁内执行
+func (sm *ShardedMap[K, V]) Update(key K, fn func(value V) V) (V, bool) {
+	s := sm.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.m[key]
+	if !ok {
+		return value, false
+	}
+	value = fn(value)
+	s.m[key] = value
+	return value, true
+}
+
+// Compute 原子地读取并修改键值，fn 接收当前值及键是否存在，返回新值及是否删除该键。
+// 返回最终的值和键是否仍然存在，fn 在分片锁内执行，不能再访问同一个 map:
+//
+//	// 计数器
+//	sm.Compute("hits", func(n int, _ bool) (int, bool) { return n + 1, false })
+func (sm *ShardedMap[K, V]) Compute(key K, fn func(value V, loaded bool) (newValue V, del bool)) (V, bool) {
+	s := sm.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, loaded := s.m[key]
+	value, del := fn(value, loaded)
+	if del {
+		delete(s.m, key)
+		var zero V
+		return zero, false
+	}
+	s.m[key] = value
+	return value, true
+}
+
+// Len 键值数量，并发写入时为近似值
+func (sm *ShardedMap[K, V]) Len() int {
+	n := 0
+	for _, s := range sm.shards {
+		s.mu.RLock()
+		n += len(s.m)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// Clear 清空所有分片
+func (sm *ShardedMap[K, V]) Clear() {
+	for _, s := range sm.shards {
+		s.mu.Lock()
+		s.m = make(map[K]V)
+		s.mu.Unlock()
+	}
+}
+
+// Snapshot 逐个分片复制键值，每个分片内的数据是一致的，不同分片的复制时间不同
+func (sm *ShardedMap[K, V]) Snapshot() map[K]V {
+	snapshot := make(map[K]V, sm.Len())
+	for _, s := range sm.shards {
+		s.mu.RLock()
+		for key, value := range s.m {
+			snapshot[key] = value
+		}
+		s.mu.RUnlock()
+	}
+	return snapshot
+}
+
+// Keys 返回所有键，顺序不固定
+func (sm *ShardedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, sm.Len())
+	for _, s := range sm.shards {
+		s.mu.RLock()
+		for key := range s.m {
+			keys = append(keys, key)
+		}
+		s.mu.RUnlock()
+	}
+	return keys
+}
+
+// Range 逐个分片复制后遍历，遍历期间不持有锁，iteratee 返回 false 时停止
+func (sm *ShardedMap[K, V]) Range(iteratee func(key K, value V) bool) {
+	type entry struct {
+		key   K
+		value V
+	}
+	var entries []entry
+	for _, s := range sm.shards {
+		s.mu.RLock()
+		entries = entries[:0]
+		for key, value := range s.m {
+			entries = append(entries, entry{key, value})
+		}
+		s.mu.RUnlock()
+
+		for _, e := range entries {
+			if !iteratee(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
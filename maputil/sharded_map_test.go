@@ -0,0 +1,188 @@
+package maputil
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedMap_Basic(t *testing.T) {
+	sm := NewShardedMap(WithShardCount[string, int](5))
+	assert.Len(t, sm.shards, 8)
+	for i := 0; i < 100; i++ {
+		sm.Set(strconv.Itoa(i), i)
+	}
+	assert.Equal(t, 100, sm.Len())
+	value, ok := sm.Get("42")
+	assert.True(t, ok)
+	assert.Equal(t, 42, value)
+	assert.True(t, sm.Delete("42"))
+	assert.False(t, sm.Delete("42"))
+	assert.False(t, sm.ContainKey("42"))
+	assert.Len(t, sm.Keys(), 99)
+	sm.Clear()
+	assert.Equal(t, 0, sm.Len())
+}
+
+func TestShardedMap_Atomic(t *testing.T) {
+	sm := NewShardedMap[string, int]()
+	actual, loaded := sm.GetOrSet("a", 1)
+	assert.Equal(t, 1, actual)
+	assert.False(t, loaded)
+	actual, loaded = sm.GetOrSet("a", 2)
+	assert.Equal(t, 1, actual)
+	assert.True(t, loaded)
+
+	value, ok := sm.Update("a", func(v int) int { return v * 10 })
+	assert.Equal(t, 10, value)
+	assert.True(t, ok)
+	_, ok = sm.Update("b", func(v int) int { return v + 1 })
+	assert.False(t, ok)
+	assert.False(t, sm.ContainKey("b"))
+
+	// Compute 删除键
+	_, ok = sm.Compute("a", func(v int, loaded bool) (int, bool) { return 0, true })
+	assert.False(t, ok)
+	assert.False(t, sm.ContainKey("a"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				sm.Compute("counter", func(n int, _ bool) (int, bool) { return n + 1, false })
+			}
+		}()
+	}
+	wg.Wait()
+	value, _ = sm.Get("counter")
+	assert.Equal(t, 5000, value)
+}
+
+func TestShardedMap_Snapshot(t *testing.T) {
+	sm := NewShardedMap[int, string]()
+	for i := 0; i < 10; i++ {
+		sm.Set(i, strconv.Itoa(i))
+	}
+	snapshot := sm.Snapshot()
+	assert.Len(t, snapshot, 10)
+	sm.Set(100, "100")
+	assert.Len(t, snapshot, 10)
+
+	n := 0
+	sm.Range(func(int, string) bool {
+		n++
+		return n < 3
+	})
+	assert.Equal(t, 3, n)
+
+	// 遍历期间不持有锁，可以修改 map
+	n = 0
+	sm.Range(func(key int, _ string) bool {
+		sm.Delete(key)
+		n++
+		return true
+	})
+	assert.Equal(t, 11, n)
+	assert.Equal(t, 0, sm.Len())
+}
+
+func TestShardedMap_Hasher(t *testing.T) {
+	type point struct{ x, y int }
+	sm := NewShardedMap(WithHasher[point, int](func(p point) uint64 { return uint64(p.x*31 + p.y) }))
+	sm.Set(point{1, 2}, 3)
+	value, ok := sm.Get(point{1, 2})
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+
+	// 默认按格式化字符串哈希
+	def := NewShardedMap[point, int]()
+	def.Set(point{1, 2}, 3)
+	assert.True(t, def.ContainKey(point{1, 2}))
+}
+
+func TestShardedMap_NegativeZero(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	sm := NewShardedMap(WithShardCount[float64, int](64))
+	sm.Set(negZero, 1)
+	sm.Set(0, 2)
+	value, ok := sm.Get(negZero)
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+	assert.Equal(t, 1, sm.Len())
+
+	type celsius float32
+	cm := NewShardedMap(WithShardCount[celsius, int](64))
+	cm.Set(celsius(negZero), 1)
+	assert.True(t, cm.ContainKey(0))
+
+	zm := NewShardedMap(WithShardCount[complex128, int](64))
+	zm.Set(complex(negZero, negZero), 1)
+	assert.True(t, zm.ContainKey(0))
+	assert.Equal(t, 1, zm.Len())
+}
+
+const benchKeys = 1024
+
+func benchKeySet() []string {
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+	return keys
+}
+
+func BenchmarkShardedMap(b *testing.B) {
+	keys := benchKeySet()
+	for _, writePercent := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("write%d%%", writePercent), func(b *testing.B) {
+			sm := NewShardedMap[string, int]()
+			for i, key := range keys {
+				sm.Set(key, i)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					key := keys[i%benchKeys]
+					if i%100 < writePercent {
+						sm.Set(key, i)
+					} else {
+						sm.Get(key)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkSyncMap(b *testing.B) {
+	keys := benchKeySet()
+	for _, writePercent := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("write%d%%", writePercent), func(b *testing.B) {
+			var m sync.Map
+			for i, key := range keys {
+				m.Store(key, i)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					key := keys[i%benchKeys]
+					if i%100 < writePercent {
+						m.Store(key, i)
+					} else {
+						m.Load(key)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
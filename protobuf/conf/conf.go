@@ -1,5 +1,105 @@
 package conf
 
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Env 运行环境，配置文件中的 app.env 仍为字符串，通过 ParseEnv 解析
+type Env string
+
+const (
+	EnvDev     Env = "dev"
+	EnvTest    Env = "test"
+	EnvStaging Env = "staging"
+	EnvProd    Env = "prod"
+)
+
+// envAliases 环境名称及其别名，不区分大小写
+var envAliases = map[string]Env{
+	"dev":         EnvDev,
+	"development": EnvDev,
+	"local":       EnvDev,
+	"test":        EnvTest,
+	"testing":     EnvTest,
+	"staging":     EnvStaging,
+	"stage":       EnvStaging,
+	"prod":        EnvProd,
+	"production":  EnvProd,
+}
+
+// ParseEnv 解析环境名称，支持 development、production 等别名，不区分大小写。
+// 不会去除首尾空白，"prod " 这类配置错误会返回错误
+func ParseEnv(s string) (Env, error) {
+	if env, ok := envAliases[strings.ToLower(s)]; ok {
+		return env, nil
+	}
+	return "", fmt.Errorf("conf: unknown env %q", s)
+}
+
+// IsValid 是否为已知环境
+func (e Env) IsValid() bool {
+	switch e {
+	case EnvDev, EnvTest, EnvStaging, EnvProd:
+		return true
+	}
+	return false
+}
+
+func (e Env) IsDevelopment() bool { return e == EnvDev }
+func (e Env) IsTest() bool        { return e == EnvTest }
+func (e Env) IsStaging() bool     { return e == EnvStaging }
+func (e Env) IsProduction() bool  { return e == EnvProd }
+
+// EnvDefaults 各环境的默认行为，供 dbutil、日志等组件读取
+type EnvDefaults struct {
+	// LogLevel 应用日志级别
+	LogLevel slog.Level
+	// LogSQL 是否记录所有执行的 SQL
+	LogSQL bool
+	// SlowQueryThreshold 慢查询阈值
+	SlowQueryThreshold time.Duration
+}
+
+// Defaults 返回环境对应的默认行为，未知环境按生产环境处理
+func (e Env) Defaults() EnvDefaults {
+	switch e {
+	case EnvDev:
+		return EnvDefaults{LogLevel: slog.LevelDebug, LogSQL: true, SlowQueryThreshold: 200 * time.Millisecond}
+	case EnvTest:
+		return EnvDefaults{LogLevel: slog.LevelDebug, SlowQueryThreshold: time.Second}
+	}
+	return EnvDefaults{LogLevel: slog.LevelInfo, SlowQueryThreshold: time.Second}
+}
+
+// Environment 返回解析后的运行环境，未配置或无法识别时按生产环境处理，避免线上漏配时开启调试行为
+func (a *App) Environment() Env {
+	env, err := ParseEnv(a.GetEnv())
+	if err != nil {
+		return EnvProd
+	}
+	return env
+}
+
 func (a *App) IsDevelopment() bool {
-	return a.Env == "dev"
+	return a.Environment().IsDevelopment()
+}
+
+func (a *App) IsTest() bool {
+	return a.Environment().IsTest()
+}
+
+func (a *App) IsStaging() bool {
+	return a.Environment().IsStaging()
+}
+
+func (a *App) IsProduction() bool {
+	return a.Environment().IsProduction()
+}
+
+// Defaults 返回当前运行环境的默认行为
+func (a *App) Defaults() EnvDefaults {
+	return a.Environment().Defaults()
 }
@@ -0,0 +1,59 @@
+package conf
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnv(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Env
+	}{
+		{"dev", EnvDev},
+		{"Development", EnvDev},
+		{"local", EnvDev},
+		{"test", EnvTest},
+		{"testing", EnvTest},
+		{"staging", EnvStaging},
+		{"stage", EnvStaging},
+		{"prod", EnvProd},
+		{"PRODUCTION", EnvProd},
+	}
+	for _, tt := range tests {
+		got, err := ParseEnv(tt.in)
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+		assert.True(t, got.IsValid())
+	}
+
+	for _, in := range []string{"", "prod ", "qa"} {
+		_, err := ParseEnv(in)
+		assert.Error(t, err, in)
+	}
+	assert.False(t, Env("qa").IsValid())
+}
+
+func TestAppEnvironment(t *testing.T) {
+	app := &App{Env: "development"}
+	assert.Equal(t, EnvDev, app.Environment())
+	assert.True(t, app.IsDevelopment())
+	assert.False(t, app.IsProduction())
+	assert.True(t, app.Defaults().LogSQL)
+	assert.Equal(t, slog.LevelDebug, app.Defaults().LogLevel)
+
+	app = &App{Env: "production"}
+	assert.True(t, app.IsProduction())
+	assert.False(t, app.Defaults().LogSQL)
+
+	assert.True(t, (&App{Env: "testing"}).IsTest())
+	assert.True(t, (&App{Env: "stage"}).IsStaging())
+
+	// 未配置或无法识别时按生产环境处理
+	assert.Equal(t, EnvProd, (&App{}).Environment())
+	assert.Equal(t, EnvProd, (&App{Env: "qa"}).Environment())
+	var nilApp *App
+	assert.True(t, nilApp.IsProduction())
+}
@@ -0,0 +1,77 @@
+package conf
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// DefaultBootstrap 返回带有默认值的根配置，可作为 confutil.Load 的目标，配置文件中的值会覆盖这些默认值
+func DefaultBootstrap() *Bootstrap {
+	return &Bootstrap{
+		Server: DefaultServer(),
+		Data:   &Data{Redis: DefaultRedis()},
+		Log:    DefaultLog(),
+		Trace:  DefaultTrace(),
+	}
+}
+
+// DefaultServer 返回 HTTP 和 gRPC 服务的默认配置
+func DefaultServer() *Server {
+	return &Server{
+		Http: &Server_HTTP{
+			Network:         "tcp",
+			Addr:            "0.0.0.0:8000",
+			ReadTimeout:     durationpb.New(10 * time.Second),
+			WriteTimeout:    durationpb.New(10 * time.Second),
+			IdleTimeout:     durationpb.New(60 * time.Second),
+			ShutdownTimeout: durationpb.New(10 * time.Second),
+		},
+		Grpc: &Server_GRPC{
+			Network:         "tcp",
+			Addr:            "0.0.0.0:9000",
+			ShutdownTimeout: durationpb.New(10 * time.Second),
+		},
+	}
+}
+
+// DefaultRedis 返回 Redis 的默认配置
+func DefaultRedis() *Data_Redis {
+	return &Data_Redis{
+		Network:      "tcp",
+		Addr:         "127.0.0.1:6379",
+		DialTimeout:  durationpb.New(5 * time.Second),
+		ReadTimeout:  durationpb.New(3 * time.Second),
+		WriteTimeout: durationpb.New(3 * time.Second),
+	}
+}
+
+// DefaultLog 返回日志的默认配置，日志级别为空，由运行环境决定
+func DefaultLog() *Log {
+	return &Log{
+		Format: Log_FORMAT_JSON,
+		Output: "stdout",
+	}
+}
+
+// DefaultTrace 返回链路追踪的默认配置，未配置 endpoint 时不启用
+func DefaultTrace() *Trace {
+	return &Trace{SampleRatio: 1}
+}
+
+// Enabled 是否启用链路追踪
+func (x *Trace) Enabled() bool {
+	return x.GetEndpoint() != ""
+}
+
+// Ratio 返回 0~1 之间的采样比例，未配置时为 1
+func (x *Trace) Ratio() float64 {
+	switch ratio := x.GetSampleRatio(); {
+	case ratio <= 0:
+		return 1
+	case ratio > 1:
+		return 1
+	default:
+		return ratio
+	}
+}
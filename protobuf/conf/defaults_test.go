@@ -0,0 +1,38 @@
+package conf
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultBootstrap(t *testing.T) {
+	c := DefaultBootstrap()
+	assert.Equal(t, "0.0.0.0:8000", c.GetServer().GetHttp().GetAddr())
+	assert.Equal(t, "0.0.0.0:9000", c.GetServer().GetGrpc().GetAddr())
+	assert.Equal(t, "127.0.0.1:6379", c.GetData().GetRedis().GetAddr())
+	assert.Equal(t, Log_FORMAT_JSON, c.GetLog().GetFormat())
+	assert.False(t, c.GetTrace().Enabled())
+
+	// 每次返回新的实例，修改互不影响
+	c.Server.Http.Addr = ":1"
+	assert.Equal(t, "0.0.0.0:8000", DefaultBootstrap().GetServer().GetHttp().GetAddr())
+}
+
+func TestTraceRatio(t *testing.T) {
+	assert.Equal(t, 1.0, (&Trace{}).Ratio())
+	assert.Equal(t, 0.1, (&Trace{SampleRatio: 0.1}).Ratio())
+	assert.Equal(t, 1.0, (&Trace{SampleRatio: 3}).Ratio())
+	assert.True(t, (&Trace{Endpoint: "collector:4317"}).Enabled())
+}
+
+func TestRedactBootstrap(t *testing.T) {
+	c := DefaultBootstrap()
+	c.Data.Redis.Password = "redis-secret"
+	c.Trace.Headers = map[string]string{"authorization": "Bearer token-value"}
+	output := fmt.Sprint(c)
+	assert.NotContains(t, output, "redis-secret")
+	assert.NotContains(t, output, "token-value")
+	assert.NotContains(t, fmt.Sprint(c.Data.Redis), "redis-secret")
+}
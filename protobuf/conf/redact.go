@@ -0,0 +1,184 @@
+package conf
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/runtime/protoimpl"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// RedactedValue 脱敏后的占位值
+const RedactedValue = "******"
+
+var (
+	// mysql 格式: user:password@tcp(host:port)/db
+	mysqlPasswordPattern = regexp.MustCompile(`^([^:@/]*):([^@]*)@`)
+	// key=value 格式: host=... password=...
+	keywordPasswordPattern = regexp.MustCompile(`(?i)(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+)
+
+// RedactDSN 将 DSN 中的密码替换为 RedactedValue，支持 mysql、URL 和 key=value 三种格式
+func RedactDSN(dsn string) string {
+	if dsn == "" {
+		return dsn
+	}
+	if strings.Contains(dsn, "://") {
+		if u, err := url.Parse(dsn); err == nil {
+			if _, ok := u.User.Password(); ok {
+				u.User = url.UserPassword(u.User.Username(), RedactedValue)
+			}
+			query := u.Query()
+			for key := range query {
+				if strings.EqualFold(key, "password") {
+					query.Set(key, RedactedValue)
+				}
+			}
+			if len(query) > 0 {
+				u.RawQuery = query.Encode()
+			}
+			// 保持占位符可读，不做 URL 编码
+			return strings.ReplaceAll(u.String(), url.QueryEscape(RedactedValue), RedactedValue)
+		}
+	}
+	if keywordPasswordPattern.MatchString(dsn) {
+		return keywordPasswordPattern.ReplaceAllString(dsn, "${1}"+RedactedValue)
+	}
+	// 密码中可能包含 @，按最后一个 @ 切分
+	if at := strings.LastIndex(dsn, "@"); at > 0 {
+		if m := mysqlPasswordPattern.FindStringSubmatch(dsn[:at+1]); m != nil {
+			return m[1] + ":" + RedactedValue + dsn[at:]
+		}
+	}
+	return dsn
+}
+
+// Redact 返回消息的副本，标记了 debug_redact 的字段被替换为 RedactedValue，
+// Data_Database.source 只隐藏其中的密码，字符串中出现的已登记敏感值被替换为 RedactedValue，用于日志输出。
+//
+// conf 包中消息的 String()、fmt 的 %v、%s 以及 slog 输出都已脱敏；proto、protojson 序列化不会脱敏
+func Redact[M proto.Message](m M) M {
+	clone := proto.Clone(m).(M)
+	redactMessage(clone.ProtoReflect())
+	return clone
+}
+
+// redactedString 生成代码中 String() 的实现，输出脱敏后的文本格式，
+// 由 Makefile 的 conf 目标在生成后替换 protoimpl.X.MessageStringOf
+func redactedString(m proto.Message) string {
+	if !m.ProtoReflect().IsValid() {
+		return protoimpl.X.MessageStringOf(m)
+	}
+	return protoimpl.X.MessageStringOf(Redact(m))
+}
+
+func redactMessage(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.FullName() == dsnField:
+			// DSN 中除密码外的地址、库名等信息对排查问题有用，只隐藏密码
+			m.Set(fd, protoreflect.ValueOfString(RedactSecrets(RedactDSN(v.String()))))
+		case isRedacted(fd):
+			redactField(m, fd)
+		case fd.IsMap():
+			v.Map().Range(func(key protoreflect.MapKey, mv protoreflect.Value) bool {
+				switch {
+				case fd.MapValue().Message() != nil:
+					redactMessage(mv.Message())
+				case fd.MapValue().Kind() == protoreflect.StringKind:
+					v.Map().Set(key, protoreflect.ValueOfString(RedactSecrets(mv.String())))
+				}
+				return true
+			})
+		case fd.IsList():
+			for i := 0; i < v.List().Len(); i++ {
+				switch {
+				case fd.Message() != nil:
+					redactMessage(v.List().Get(i).Message())
+				case fd.Kind() == protoreflect.StringKind:
+					v.List().Set(i, protoreflect.ValueOfString(RedactSecrets(v.List().Get(i).String())))
+				}
+			}
+		case fd.Message() != nil:
+			redactMessage(v.Message())
+		case fd.Kind() == protoreflect.StringKind:
+			// 配置加载时登记的密钥（见 MarkSensitive）可能出现在任意字符串字段中
+			m.Set(fd, protoreflect.ValueOfString(RedactSecrets(v.String())))
+		}
+		return true
+	})
+}
+
+// dsnField Data_Database.source 的完整字段名
+const dsnField protoreflect.FullName = "conf.Data.Database.source"
+
+// isRedacted 字段是否标记了 [debug_redact = true]
+func isRedacted(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+	return ok && opts.GetDebugRedact()
+}
+
+// redactField 字符串字段替换为 RedactedValue，其他类型的字段直接清空
+func redactField(m protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	if fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap() {
+		m.Set(fd, protoreflect.ValueOfString(RedactedValue))
+		return
+	}
+	m.Clear(fd)
+}
+
+// Format 实现 fmt.Formatter，打印时对敏感字段脱敏
+func (x *Data) Format(f fmt.State, verb rune) {
+	formatRedacted(f, x)
+}
+
+// LogValue 实现 slog.LogValuer，日志输出时对敏感字段脱敏
+func (x *Data) LogValue() slog.Value {
+	return slog.StringValue(x.String())
+}
+
+// Format 实现 fmt.Formatter，打印时对密码和 DSN 脱敏
+func (x *Data_Database) Format(f fmt.State, verb rune) {
+	formatRedacted(f, x)
+}
+
+// LogValue 实现 slog.LogValuer，日志输出时对密码和 DSN 脱敏
+func (x *Data_Database) LogValue() slog.Value {
+	return slog.StringValue(x.String())
+}
+
+// Format 实现 fmt.Formatter，打印时对敏感字段脱敏
+func (x *Bootstrap) Format(f fmt.State, verb rune) {
+	formatRedacted(f, x)
+}
+
+// LogValue 实现 slog.LogValuer，日志输出时对敏感字段脱敏
+func (x *Bootstrap) LogValue() slog.Value {
+	return slog.StringValue(x.String())
+}
+
+// Format 实现 fmt.Formatter，打印时对密码脱敏
+func (x *Data_Redis) Format(f fmt.State, verb rune) {
+	formatRedacted(f, x)
+}
+
+// LogValue 实现 slog.LogValuer，日志输出时对密码脱敏
+func (x *Data_Redis) LogValue() slog.Value {
+	return slog.StringValue(x.String())
+}
+
+func formatRedacted[M interface {
+	proto.Message
+	String() string
+}](f fmt.State, m M) {
+	if !m.ProtoReflect().IsValid() {
+		_, _ = fmt.Fprint(f, "<nil>")
+		return
+	}
+	_, _ = fmt.Fprint(f, m.String())
+}
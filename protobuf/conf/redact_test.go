@@ -0,0 +1,68 @@
+package conf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	data := &Data{Database: &Data_Database{
+		Source:   "root:secret@tcp(127.0.0.1:3306)/test",
+		User:     "root",
+		Password: "secret",
+	}}
+	redacted := Redact(data)
+	assert.Equal(t, RedactedValue, redacted.Database.Password)
+	assert.Equal(t, "root:******@tcp(127.0.0.1:3306)/test", redacted.Database.Source)
+	assert.Equal(t, "secret", data.Database.Password)
+
+	for _, s := range []string{fmt.Sprint(data), fmt.Sprintf("%v", data.Database), fmt.Sprintf("%+v", data), fmt.Sprintf("%s", data.Database)} {
+		assert.False(t, strings.Contains(s, "secret"), s)
+		assert.True(t, strings.Contains(s, RedactedValue), s)
+	}
+	assert.False(t, strings.Contains(data.Database.LogValue().String(), "secret"))
+
+	var empty *Data_Database
+	assert.Equal(t, "<nil>", fmt.Sprint(empty))
+	assert.Equal(t, "<nil>", empty.String())
+}
+
+func TestRedactString(t *testing.T) {
+	db := &Data_Database{Source: "root:secretpw@tcp(h:3306)/db", Password: "pw12345"}
+	for _, s := range []string{db.String(), (&Data{Database: db}).String(), (&Bootstrap{Data: &Data{Database: db}}).String()} {
+		assert.NotContains(t, s, "secretpw")
+		assert.NotContains(t, s, "pw12345")
+		assert.Contains(t, s, "tcp(h:3306)/db")
+	}
+	// String() 不修改原消息
+	assert.Equal(t, "pw12345", db.Password)
+
+	redis := &Data_Redis{Addr: "redis:6379", Password: "redis-secret"}
+	assert.NotContains(t, redis.String(), "redis-secret")
+	trace := &Trace{Headers: map[string]string{"authorization": "Bearer trace-token"}}
+	assert.NotContains(t, trace.String(), "trace-token")
+}
+
+func TestRedactSensitiveValues(t *testing.T) {
+	MarkSensitive("s3cr3t-from-env", "q7", "")
+	assert.True(t, IsSensitive("s3cr3t-from-env"))
+	assert.True(t, IsSensitive("q7"), "short secrets are registered too")
+	assert.False(t, IsSensitive(""))
+
+	assert.Equal(t, "token="+RedactedValue, RedactSecrets("token=s3cr3t-from-env"))
+	assert.Equal(t, "pin="+RedactedValue, RedactSecrets("pin=q7"))
+	assert.Equal(t, "abc", RedactSecrets("abc"))
+
+	db := &Data_Database{
+		Host:   "s3cr3t-from-env.internal",
+		Params: map[string]string{"token": "s3cr3t-from-env"},
+	}
+	redacted := Redact(db)
+	assert.Equal(t, RedactedValue+".internal", redacted.Host)
+	assert.Equal(t, RedactedValue, redacted.Params["token"])
+	assert.Equal(t, "s3cr3t-from-env", db.Params["token"])
+	assert.NotContains(t, fmt.Sprint(db), "s3cr3t-from-env")
+}
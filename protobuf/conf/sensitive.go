@@ -0,0 +1,68 @@
+package conf
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// sensitive 进程内登记的敏感值，例如配置加载时从 ${env:...}、${file:...} 解析出的密钥
+var sensitive struct {
+	sync.RWMutex
+	values   map[string]struct{}
+	replacer *strings.Replacer
+}
+
+// MarkSensitive 登记敏感值，之后 Redact、RedactSecrets 以及 Format、LogValue 输出时
+// 所有字符串中出现的这些值都会被替换为 RedactedValue。
+// 除空字符串外的值都会登记，过短的值可能把普通文本一起替换掉，但不会漏掉密钥
+func MarkSensitive(values ...string) {
+	sensitive.Lock()
+	defer sensitive.Unlock()
+	if sensitive.values == nil {
+		sensitive.values = make(map[string]struct{})
+	}
+	changed := false
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if _, ok := sensitive.values[value]; !ok {
+			sensitive.values[value] = struct{}{}
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	// 较长的值优先匹配，避免一个密钥是另一个的前缀时只替换一部分
+	keys := make([]string, 0, len(sensitive.values))
+	for value := range sensitive.values {
+		keys = append(keys, value)
+	}
+	slices.SortFunc(keys, func(a, b string) int { return len(b) - len(a) })
+	pairs := make([]string, 0, len(keys)*2)
+	for _, value := range keys {
+		pairs = append(pairs, value, RedactedValue)
+	}
+	sensitive.replacer = strings.NewReplacer(pairs...)
+}
+
+// IsSensitive 是否为登记过的敏感值
+func IsSensitive(value string) bool {
+	sensitive.RLock()
+	defer sensitive.RUnlock()
+	_, ok := sensitive.values[value]
+	return ok
+}
+
+// RedactSecrets 将字符串中出现的已登记敏感值替换为 RedactedValue
+func RedactSecrets(s string) string {
+	sensitive.RLock()
+	replacer := sensitive.replacer
+	sensitive.RUnlock()
+	if replacer == nil || s == "" {
+		return s
+	}
+	return replacer.Replace(s)
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.33.0
+// 	protoc-gen-go v1.34.2
 // 	protoc        v5.26.1
 // source: pagination.proto
 
@@ -20,6 +20,104 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Sort_Direction int32
+
+const (
+	Sort_DIRECTION_UNSPECIFIED Sort_Direction = 0 // 默认升序
+	Sort_DIRECTION_ASC         Sort_Direction = 1
+	Sort_DIRECTION_DESC        Sort_Direction = 2
+)
+
+// Enum value maps for Sort_Direction.
+var (
+	Sort_Direction_name = map[int32]string{
+		0: "DIRECTION_UNSPECIFIED",
+		1: "DIRECTION_ASC",
+		2: "DIRECTION_DESC",
+	}
+	Sort_Direction_value = map[string]int32{
+		"DIRECTION_UNSPECIFIED": 0,
+		"DIRECTION_ASC":         1,
+		"DIRECTION_DESC":        2,
+	}
+)
+
+func (x Sort_Direction) Enum() *Sort_Direction {
+	p := new(Sort_Direction)
+	*p = x
+	return p
+}
+
+func (x Sort_Direction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Sort_Direction) Descriptor() protoreflect.EnumDescriptor {
+	return file_pagination_proto_enumTypes[0].Descriptor()
+}
+
+func (Sort_Direction) Type() protoreflect.EnumType {
+	return &file_pagination_proto_enumTypes[0]
+}
+
+func (x Sort_Direction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Sort_Direction.Descriptor instead.
+func (Sort_Direction) EnumDescriptor() ([]byte, []int) {
+	return file_pagination_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type Sort_Nulls int32
+
+const (
+	Sort_NULLS_UNSPECIFIED Sort_Nulls = 0 // 数据库默认行为，内存排序时空值视为最小
+	Sort_NULLS_FIRST       Sort_Nulls = 1
+	Sort_NULLS_LAST        Sort_Nulls = 2
+)
+
+// Enum value maps for Sort_Nulls.
+var (
+	Sort_Nulls_name = map[int32]string{
+		0: "NULLS_UNSPECIFIED",
+		1: "NULLS_FIRST",
+		2: "NULLS_LAST",
+	}
+	Sort_Nulls_value = map[string]int32{
+		"NULLS_UNSPECIFIED": 0,
+		"NULLS_FIRST":       1,
+		"NULLS_LAST":        2,
+	}
+)
+
+func (x Sort_Nulls) Enum() *Sort_Nulls {
+	p := new(Sort_Nulls)
+	*p = x
+	return p
+}
+
+func (x Sort_Nulls) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Sort_Nulls) Descriptor() protoreflect.EnumDescriptor {
+	return file_pagination_proto_enumTypes[1].Descriptor()
+}
+
+func (Sort_Nulls) Type() protoreflect.EnumType {
+	return &file_pagination_proto_enumTypes[1]
+}
+
+func (x Sort_Nulls) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Sort_Nulls.Descriptor instead.
+func (Sort_Nulls) EnumDescriptor() ([]byte, []int) {
+	return file_pagination_proto_rawDescGZIP(), []int{1, 1}
+}
+
 type Pagination struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -27,6 +125,10 @@ type Pagination struct {
 
 	Page     int32 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
 	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// 游标分页时上一页返回的 next_cursor，为空时按 page 分页
+	Cursor string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// 排序规则，按顺序依次排序，可由 "-created_at,name" 这样的字符串解析得到
+	Sort []*Sort `protobuf:"bytes,4,rep,name=sort,proto3" json:"sort,omitempty"`
 }
 
 func (x *Pagination) Reset() {
@@ -75,17 +177,234 @@ func (x *Pagination) GetPageSize() int32 {
 	return 0
 }
 
+func (x *Pagination) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *Pagination) GetSort() []*Sort {
+	if x != nil {
+		return x.Sort
+	}
+	return nil
+}
+
+// Sort 单个字段的排序规则
+type Sort struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Field     string         `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Direction Sort_Direction `protobuf:"varint,2,opt,name=direction,proto3,enum=pagination.Sort_Direction" json:"direction,omitempty"`
+	Nulls     Sort_Nulls     `protobuf:"varint,3,opt,name=nulls,proto3,enum=pagination.Sort_Nulls" json:"nulls,omitempty"`
+}
+
+func (x *Sort) Reset() {
+	*x = Sort{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pagination_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Sort) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sort) ProtoMessage() {}
+
+func (x *Sort) ProtoReflect() protoreflect.Message {
+	mi := &file_pagination_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sort.ProtoReflect.Descriptor instead.
+func (*Sort) Descriptor() ([]byte, []int) {
+	return file_pagination_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Sort) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Sort) GetDirection() Sort_Direction {
+	if x != nil {
+		return x.Direction
+	}
+	return Sort_DIRECTION_UNSPECIFIED
+}
+
+func (x *Sort) GetNulls() Sort_Nulls {
+	if x != nil {
+		return x.Nulls
+	}
+	return Sort_NULLS_UNSPECIFIED
+}
+
+// PaginationReply 分页响应，与 generalutil/pagination.Builder 字段一一对应
+type PaginationReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Page       int32  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize   int32  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Total      int64  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	TotalPages int32  `protobuf:"varint,4,opt,name=total_pages,json=totalPages,proto3" json:"total_pages,omitempty"`
+	Next       int32  `protobuf:"varint,5,opt,name=next,proto3" json:"next,omitempty"` // 下一页页码，没有下一页时为 0
+	Prev       int32  `protobuf:"varint,6,opt,name=prev,proto3" json:"prev,omitempty"` // 上一页页码，没有上一页时为 0
+	HasMore    bool   `protobuf:"varint,7,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	NextCursor string `protobuf:"bytes,8,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // 游标分页时下一页的游标，没有更多数据时为空
+}
+
+func (x *PaginationReply) Reset() {
+	*x = PaginationReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pagination_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PaginationReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaginationReply) ProtoMessage() {}
+
+func (x *PaginationReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pagination_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaginationReply.ProtoReflect.Descriptor instead.
+func (*PaginationReply) Descriptor() ([]byte, []int) {
+	return file_pagination_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PaginationReply) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *PaginationReply) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *PaginationReply) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *PaginationReply) GetTotalPages() int32 {
+	if x != nil {
+		return x.TotalPages
+	}
+	return 0
+}
+
+func (x *PaginationReply) GetNext() int32 {
+	if x != nil {
+		return x.Next
+	}
+	return 0
+}
+
+func (x *PaginationReply) GetPrev() int32 {
+	if x != nil {
+		return x.Prev
+	}
+	return 0
+}
+
+func (x *PaginationReply) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *PaginationReply) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
 var File_pagination_proto protoreflect.FileDescriptor
 
 var file_pagination_proto_rawDesc = []byte{
 	0x0a, 0x10, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x12, 0x0a, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x3d,
+	0x74, 0x6f, 0x12, 0x0a, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x7b,
 	0x0a, 0x0a, 0x50, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04,
 	0x70, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65,
 	0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x0e, 0x5a,
-	0x0c, 0x2e, 0x3b, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63,
+	0x75, 0x72, 0x73, 0x6f, 0x72, 0x12, 0x24, 0x0a, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x53, 0x6f, 0x72, 0x74, 0x52, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x22, 0x94, 0x02, 0x0a, 0x04,
+	0x53, 0x6f, 0x72, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x38, 0x0a, 0x09, 0x64, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e,
+	0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x2e,
+	0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x64, 0x69, 0x72, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x05, 0x6e, 0x75, 0x6c, 0x6c, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x53, 0x6f, 0x72, 0x74, 0x2e, 0x4e, 0x75, 0x6c, 0x6c, 0x73, 0x52, 0x05, 0x6e, 0x75, 0x6c,
+	0x6c, 0x73, 0x22, 0x4d, 0x0a, 0x09, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x19, 0x0a, 0x15, 0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x53,
+	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x44, 0x49,
+	0x52, 0x45, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x41, 0x53, 0x43, 0x10, 0x01, 0x12, 0x12, 0x0a,
+	0x0e, 0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x44, 0x45, 0x53, 0x43, 0x10,
+	0x02, 0x22, 0x3f, 0x0a, 0x05, 0x4e, 0x75, 0x6c, 0x6c, 0x73, 0x12, 0x15, 0x0a, 0x11, 0x4e, 0x55,
+	0x4c, 0x4c, 0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x4e, 0x55, 0x4c, 0x4c, 0x53, 0x5f, 0x46, 0x49, 0x52, 0x53, 0x54,
+	0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x4e, 0x55, 0x4c, 0x4c, 0x53, 0x5f, 0x4c, 0x41, 0x53, 0x54,
+	0x10, 0x02, 0x22, 0xdd, 0x01, 0x0a, 0x0f, 0x50, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61,
+	0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70,
+	0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x1f, 0x0a,
+	0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x50, 0x61, 0x67, 0x65, 0x73, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x65, 0x78, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6e, 0x65,
+	0x78, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x72, 0x65, 0x76, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x04, 0x70, 0x72, 0x65, 0x76, 0x12, 0x19, 0x0a, 0x08, 0x68, 0x61, 0x73, 0x5f, 0x6d, 0x6f,
+	0x72, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x68, 0x61, 0x73, 0x4d, 0x6f, 0x72,
+	0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72, 0x73,
+	0x6f, 0x72, 0x42, 0x0e, 0x5a, 0x0c, 0x2e, 0x3b, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -100,16 +419,24 @@ func file_pagination_proto_rawDescGZIP() []byte {
 	return file_pagination_proto_rawDescData
 }
 
-var file_pagination_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
-var file_pagination_proto_goTypes = []interface{}{
-	(*Pagination)(nil), // 0: pagination.Pagination
+var file_pagination_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_pagination_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_pagination_proto_goTypes = []any{
+	(Sort_Direction)(0),     // 0: pagination.Sort.Direction
+	(Sort_Nulls)(0),         // 1: pagination.Sort.Nulls
+	(*Pagination)(nil),      // 2: pagination.Pagination
+	(*Sort)(nil),            // 3: pagination.Sort
+	(*PaginationReply)(nil), // 4: pagination.PaginationReply
 }
 var file_pagination_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	3, // 0: pagination.Pagination.sort:type_name -> pagination.Sort
+	0, // 1: pagination.Sort.direction:type_name -> pagination.Sort.Direction
+	1, // 2: pagination.Sort.nulls:type_name -> pagination.Sort.Nulls
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_pagination_proto_init() }
@@ -118,7 +445,7 @@ func file_pagination_proto_init() {
 		return
 	}
 	if !protoimpl.UnsafeEnabled {
-		file_pagination_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+		file_pagination_proto_msgTypes[0].Exporter = func(v any, i int) any {
 			switch v := v.(*Pagination); i {
 			case 0:
 				return &v.state
@@ -130,19 +457,44 @@ func file_pagination_proto_init() {
 				return nil
 			}
 		}
+		file_pagination_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Sort); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pagination_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*PaginationReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pagination_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   1,
+			NumEnums:      2,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_pagination_proto_goTypes,
 		DependencyIndexes: file_pagination_proto_depIdxs,
+		EnumInfos:         file_pagination_proto_enumTypes,
 		MessageInfos:      file_pagination_proto_msgTypes,
 	}.Build()
 	File_pagination_proto = out.File
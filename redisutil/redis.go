@@ -0,0 +1,64 @@
+package redisutil
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+// Options 将 Redis 配置转换为 go-redis 的连接选项，未配置的字段使用 conf.DefaultRedis 中的默认值
+func Options(c *conf.Data_Redis) *redis.Options {
+	defaults := conf.DefaultRedis()
+	opts := &redis.Options{
+		Network:      c.GetNetwork(),
+		Addr:         c.GetAddr(),
+		Username:     c.GetUsername(),
+		Password:     c.GetPassword(),
+		DB:           int(c.GetDb()),
+		DialTimeout:  c.GetDialTimeout().AsDuration(),
+		ReadTimeout:  c.GetReadTimeout().AsDuration(),
+		WriteTimeout: c.GetWriteTimeout().AsDuration(),
+		PoolSize:     int(c.GetPoolSize()),
+		MinIdleConns: int(c.GetMinIdleConns()),
+	}
+	if opts.Network == "" {
+		opts.Network = defaults.GetNetwork()
+	}
+	if opts.Addr == "" {
+		opts.Addr = defaults.GetAddr()
+	}
+	if c.GetDialTimeout() == nil {
+		opts.DialTimeout = defaults.GetDialTimeout().AsDuration()
+	}
+	if c.GetReadTimeout() == nil {
+		opts.ReadTimeout = defaults.GetReadTimeout().AsDuration()
+	}
+	if c.GetWriteTimeout() == nil {
+		opts.WriteTimeout = defaults.GetWriteTimeout().AsDuration()
+	}
+	if c.GetTls() {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return opts
+}
+
+// New 按配置创建 Redis 客户端并检查连通性，连接失败时关闭客户端并返回错误。
+// 连通性检查的超时时间为 dial_timeout，未配置或为 0 时使用默认值
+func New(ctx context.Context, c *conf.Data_Redis) (*redis.Client, error) {
+	opts := Options(c)
+	client := redis.NewClient(opts)
+	timeout := opts.DialTimeout
+	if timeout <= 0 {
+		timeout = conf.DefaultRedis().GetDialTimeout().AsDuration()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return client, nil
+}
@@ -0,0 +1,52 @@
+package redisutil
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+func TestOptions(t *testing.T) {
+	opts := Options(&conf.Data_Redis{})
+	assert.Equal(t, "tcp", opts.Network)
+	assert.Equal(t, "127.0.0.1:6379", opts.Addr)
+	assert.Equal(t, 5*time.Second, opts.DialTimeout)
+	assert.Equal(t, 3*time.Second, opts.ReadTimeout)
+	assert.Nil(t, opts.TLSConfig)
+
+	opts = Options(&conf.Data_Redis{
+		Addr:        "redis:6380",
+		Password:    "secret",
+		Db:          2,
+		ReadTimeout: durationpb.New(time.Second),
+		PoolSize:    20,
+		Tls:         true,
+	})
+	assert.Equal(t, "redis:6380", opts.Addr)
+	assert.Equal(t, "secret", opts.Password)
+	assert.Equal(t, 2, opts.DB)
+	assert.Equal(t, time.Second, opts.ReadTimeout)
+	assert.Equal(t, 3*time.Second, opts.WriteTimeout)
+	assert.Equal(t, 20, opts.PoolSize)
+	assert.NotNil(t, opts.TLSConfig)
+}
+
+func TestNew(t *testing.T) {
+	// dial_timeout 为 0 时连通性检查使用默认超时，不会立即失败
+	client, err := New(context.Background(), &conf.Data_Redis{DialTimeout: durationpb.New(0)})
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		// 依赖本地 Redis，未启动时跳过，其他错误仍视为失败
+		t.Skipf("New error: %v", err)
+	}
+	require.NoError(t, err)
+	defer client.Close()
+	assert.NoError(t, client.Set(context.Background(), "redisutil:test", "1", time.Second).Err())
+}
@@ -0,0 +1,145 @@
+package serverutil
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+// NewHTTPServer 按配置创建 http.Server，未配置的字段使用 conf.DefaultServer 中的默认值，
+// 配置了 timeout 时使用 http.TimeoutHandler 限制单个请求的处理时间
+func NewHTTPServer(c *conf.Server_HTTP, handler http.Handler) *http.Server {
+	defaults := conf.DefaultServer().GetHttp()
+	if timeout := c.GetTimeout().AsDuration(); timeout > 0 {
+		handler = http.TimeoutHandler(handler, timeout, "")
+	}
+	return &http.Server{
+		Addr:         orDefault(c.GetAddr(), defaults.GetAddr()),
+		Handler:      handler,
+		ReadTimeout:  durationOrDefault(c.GetReadTimeout(), defaults.GetReadTimeout()),
+		WriteTimeout: durationOrDefault(c.GetWriteTimeout(), defaults.GetWriteTimeout()),
+		IdleTimeout:  durationOrDefault(c.GetIdleTimeout(), defaults.GetIdleTimeout()),
+	}
+}
+
+// RunHTTP 按配置监听并启动 HTTP 服务，阻塞直到 ctx 结束或服务出错，
+// ctx 结束时在 shutdown_timeout 内等待处理中的请求完成后退出
+func RunHTTP(ctx context.Context, c *conf.Server_HTTP, handler http.Handler) error {
+	defaults := conf.DefaultServer().GetHttp()
+	lis, err := net.Listen(orDefault(c.GetNetwork(), defaults.GetNetwork()), orDefault(c.GetAddr(), defaults.GetAddr()))
+	if err != nil {
+		return err
+	}
+	srv := NewHTTPServer(c, handler)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(lis)
+	}()
+	select {
+	case err = <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), durationOrDefault(c.GetShutdownTimeout(), defaults.GetShutdownTimeout()))
+	defer cancel()
+	if err = srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err = <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NewGRPCServer 按配置创建 grpc.Server，opts 追加在配置生成的选项之后。
+// 配置了 max_recv_msg_size、max_send_msg_size 时限制消息大小，配置了 timeout 时为一元调用设置处理超时，
+// 流式调用的生命周期由客户端决定，不受 timeout 限制
+func NewGRPCServer(c *conf.Server_GRPC, opts ...grpc.ServerOption) *grpc.Server {
+	var serverOpts []grpc.ServerOption
+	if size := c.GetMaxRecvMsgSize(); size > 0 {
+		serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(int(size)))
+	}
+	if size := c.GetMaxSendMsgSize(); size > 0 {
+		serverOpts = append(serverOpts, grpc.MaxSendMsgSize(int(size)))
+	}
+	if timeout := c.GetTimeout().AsDuration(); timeout > 0 {
+		serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(timeoutInterceptor(timeout)))
+	}
+	return grpc.NewServer(append(serverOpts, opts...)...)
+}
+
+// timeoutInterceptor 为一元调用设置处理超时，客户端设置了更短的 deadline 时以客户端为准
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// RunGRPC 按配置监听并启动 gRPC 服务，阻塞直到 ctx 结束或服务出错，
+// ctx 结束时在 shutdown_timeout 内等待处理中的请求完成，超时后强制退出:
+//
+//	srv := serverutil.NewGRPCServer(c.GetServer().GetGrpc())
+//	pb.RegisterGreeterServer(srv, greeter)
+//	err := serverutil.RunGRPC(ctx, c.GetServer().GetGrpc(), srv)
+func RunGRPC(ctx context.Context, c *conf.Server_GRPC, srv *grpc.Server) error {
+	lis, err := ListenGRPC(c)
+	if err != nil {
+		return err
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(lis)
+	}()
+	select {
+	case err = <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	timer := time.NewTimer(GRPCShutdownTimeout(c))
+	defer timer.Stop()
+	select {
+	case <-stopped:
+	case <-timer.C:
+		srv.Stop()
+	}
+	return <-errCh
+}
+
+// ListenGRPC 按配置监听 gRPC 服务地址，需要自行管理 grpc.Server 时使用，一般直接使用 RunGRPC
+func ListenGRPC(c *conf.Server_GRPC) (net.Listener, error) {
+	defaults := conf.DefaultServer().GetGrpc()
+	return net.Listen(orDefault(c.GetNetwork(), defaults.GetNetwork()), orDefault(c.GetAddr(), defaults.GetAddr()))
+}
+
+// GRPCShutdownTimeout 返回 gRPC 服务优雅退出的等待时间，超时后应调用 grpc.Server.Stop 强制退出
+func GRPCShutdownTimeout(c *conf.Server_GRPC) time.Duration {
+	return durationOrDefault(c.GetShutdownTimeout(), conf.DefaultServer().GetGrpc().GetShutdownTimeout())
+}
+
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+func durationOrDefault(value, def *durationpb.Duration) time.Duration {
+	if value == nil {
+		return def.AsDuration()
+	}
+	return value.AsDuration()
+}
@@ -0,0 +1,111 @@
+package serverutil
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+func TestNewHTTPServer(t *testing.T) {
+	srv := NewHTTPServer(nil, http.NotFoundHandler())
+	assert.Equal(t, "0.0.0.0:8000", srv.Addr)
+	assert.Equal(t, 10*time.Second, srv.ReadTimeout)
+	assert.Equal(t, 60*time.Second, srv.IdleTimeout)
+
+	srv = NewHTTPServer(&conf.Server_HTTP{
+		Addr:        "127.0.0.1:8080",
+		ReadTimeout: durationpb.New(time.Second),
+	}, http.NotFoundHandler())
+	assert.Equal(t, "127.0.0.1:8080", srv.Addr)
+	assert.Equal(t, time.Second, srv.ReadTimeout)
+	assert.Equal(t, 10*time.Second, srv.WriteTimeout)
+}
+
+func TestRunHTTP(t *testing.T) {
+	lis, err := ListenGRPC(&conf.Server_GRPC{Addr: "127.0.0.1:0"})
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- RunHTTP(ctx, &conf.Server_HTTP{Addr: addr}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		}))
+	}()
+
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		resp, err = http.Get("http://" + addr)
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "ok", string(body))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestGRPCShutdownTimeout(t *testing.T) {
+	assert.Equal(t, 10*time.Second, GRPCShutdownTimeout(nil))
+	assert.Equal(t, time.Second, GRPCShutdownTimeout(&conf.Server_GRPC{ShutdownTimeout: durationpb.New(time.Second)}))
+}
+
+func TestTimeoutInterceptor(t *testing.T) {
+	interceptor := timeoutInterceptor(time.Second)
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+		return nil, nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestRunGRPC(t *testing.T) {
+	lis, err := ListenGRPC(&conf.Server_GRPC{Addr: "127.0.0.1:0"})
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	c := &conf.Server_GRPC{Addr: addr, Timeout: durationpb.New(time.Second), MaxRecvMsgSize: 1 << 10}
+	srv := NewGRPCServer(c)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- RunGRPC(ctx, c, srv)
+	}()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	require.Eventually(t, func() bool {
+		_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// 超过 max_recv_msg_size 的请求被拒绝
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: string(make([]byte, 2<<10))})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,64 @@
+package traceutil
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+// Options 将链路追踪配置转换为 OTLP gRPC 导出器选项
+func Options(c *conf.Trace) []otlptracegrpc.Option {
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(c.GetEndpoint())}
+	if c.GetInsecure() {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	if len(c.GetHeaders()) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(c.GetHeaders()))
+	}
+	return opts
+}
+
+// New 按链路追踪配置创建 TracerProvider，按 sample_ratio 采样并通过 OTLP gRPC 上报，
+// app 的名称、版本和运行环境写入 resource。未配置 endpoint 时返回不上报的 TracerProvider。
+// opts 追加在配置生成的选项之后，退出前需调用 Shutdown 上报剩余数据:
+//
+//	tp, err := traceutil.New(ctx, c.GetTrace(), c.GetApp())
+//	if err != nil {
+//		return err
+//	}
+//	defer tp.Shutdown(context.Background())
+//	otel.SetTracerProvider(tp)
+func New(ctx context.Context, c *conf.Trace, app *conf.App, opts ...sdktrace.TracerProviderOption) (*sdktrace.TracerProvider, error) {
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.Ratio()))),
+		sdktrace.WithResource(appResource(app)),
+	}
+	if c.Enabled() {
+		exporter, err := otlptracegrpc.New(ctx, Options(c)...)
+		if err != nil {
+			return nil, err
+		}
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+	return sdktrace.NewTracerProvider(append(providerOpts, opts...)...), nil
+}
+
+// appResource 按应用配置生成 resource，属性名遵循 OpenTelemetry 语义约定
+func appResource(app *conf.App) *resource.Resource {
+	var attrs []attribute.KeyValue
+	if app.GetName() != "" {
+		attrs = append(attrs, attribute.String("service.name", app.GetName()))
+	}
+	if app.GetVersion() != "" {
+		attrs = append(attrs, attribute.String("service.version", app.GetVersion()))
+	}
+	if app.GetEnv() != "" {
+		attrs = append(attrs, attribute.String("deployment.environment", string(app.Environment())))
+	}
+	return resource.NewSchemaless(attrs...)
+}
@@ -0,0 +1,44 @@
+package traceutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/lastares/claymore/protobuf/conf"
+)
+
+func TestOptions(t *testing.T) {
+	assert.Len(t, Options(&conf.Trace{Endpoint: "collector:4317"}), 1)
+	assert.Len(t, Options(&conf.Trace{
+		Endpoint: "collector:4317",
+		Insecure: true,
+		Headers:  map[string]string{"authorization": "Bearer token"},
+	}), 3)
+}
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+	app := &conf.App{Name: "order", Version: "v1.0.0", Env: "production"}
+
+	// 未配置 endpoint 时不上报
+	tp, err := New(ctx, nil, app)
+	require.NoError(t, err)
+	_, span := tp.Tracer("test").Start(ctx, "op")
+	assert.True(t, span.SpanContext().IsSampled())
+	span.End()
+	assert.NoError(t, tp.Shutdown(ctx))
+
+	tp, err = New(ctx, &conf.Trace{Endpoint: "127.0.0.1:4317", Insecure: true, SampleRatio: 0.5}, app)
+	require.NoError(t, err)
+	defer tp.Shutdown(context.Background())
+
+	attrs := appResource(app).Set()
+	name, _ := attrs.Value(attribute.Key("service.name"))
+	assert.Equal(t, "order", name.AsString())
+	env, _ := attrs.Value(attribute.Key("deployment.environment"))
+	assert.Equal(t, "prod", env.AsString())
+}